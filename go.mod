@@ -6,6 +6,7 @@ toolchain go1.24.6
 
 require (
 	github.com/jedib0t/go-pretty/v6 v6.6.8
+	github.com/pelletier/go-toml/v2 v2.3.1
 	github.com/urfave/cli/v3 v3.3.8
 	go.yaml.in/yaml/v3 v3.0.4
 )
@@ -2,27 +2,35 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	_ "time/tzdata"
 
 	"github.com/guessi/ssl-certs-checker/pkg/app"
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
 	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/output"
+	"github.com/guessi/ssl-certs-checker/pkg/server"
 	"github.com/urfave/cli/v3"
 )
 
-const defaultDialerTimeout = 5
+const (
+	defaultDialerTimeout        = 5
+	defaultHistoryRetentionDays = 90
+)
 
 func main() {
 	cliApp := &cli.Command{
 		Usage: "check SSL certificates at once",
 		Flags: []cli.Flag{
-			&cli.StringFlag{
+			&cli.StringSliceFlag{
 				Name:     "config",
 				Aliases:  []string{"C"},
-				Value:    "",
-				Usage:    "config file",
+				Usage:    "config file or glob pattern (e.g., 'conf.d/*.yaml'), - for stdin, hosts of all files are merged (can be repeated)",
 				Required: false,
 			},
 			&cli.StringFlag{
@@ -32,6 +40,35 @@ func main() {
 				Usage:    "comma-separated list of domains to check (e.g., example.com,google.com:443)",
 				Required: false,
 			},
+			&cli.StringFlag{
+				Name:     "hosts-from",
+				Value:    "",
+				Usage:    "file listing one host per line with # comments, - for stdin",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "hosts-csv",
+				Value:    "",
+				Usage:    "CSV inventory with a header row to read hosts from, - for stdin",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "host-column",
+				Value:    "",
+				Usage:    "column of --hosts-csv holding the hosts (default: host)",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "port-column",
+				Value:    "",
+				Usage:    "column of --hosts-csv holding the ports",
+				Required: false,
+			},
+			&cli.StringSliceFlag{
+				Name:     "tag-columns",
+				Usage:    "columns of --hosts-csv whose values become tags and labels of the hosts (e.g., team,env)",
+				Required: false,
+			},
 			&cli.IntFlag{
 				Name:     "timeout",
 				Aliases:  []string{"t"},
@@ -49,42 +86,384 @@ func main() {
 			&cli.StringFlag{
 				Name:     "output",
 				Aliases:  []string{"o"},
-				Value:    "table",
-				Usage:    "output format (table, json, yaml)",
+				Value:    output.DefaultFormat,
+				Usage:    "output format (" + strings.Join(output.Names(), ", ") + ")",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "template",
+				Value:    "",
+				Usage:    "Go text/template, inline or as a file path, used by the template output format",
+				Required: false,
+			},
+			&cli.StringSliceFlag{
+				Name:     "output-file",
+				Usage:    "also write the results to a file as [format=]path, atomically replacing it (can be repeated)",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "textfile",
+				Value:    "",
+				Usage:    "also write Prometheus metrics to this node_exporter textfile collector file or directory",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "no-progress",
+				Value:    false,
+				Usage:    "disable the live progress display shown for table output on terminals",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "no-color",
+				Value:    false,
+				Usage:    "disable colored output, also honoring the NO_COLOR environment variable",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "compact",
+				Value:    false,
+				Usage:    "truncate long DNS name lists in table output",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "group-by",
+				Value:    "",
+				Usage:    "group table output by the value of this label (e.g., team)",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "time-format",
+				Value:    output.DefaultTimeFormat,
+				Usage:    "timestamp layout in table output, a Go time layout or one of RFC3339, RFC1123, DateOnly, DateTime",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "timezone",
+				Value:    "UTC",
+				Usage:    "time zone of timestamps in table output (e.g., Local, Asia/Taipei)",
+				Required: false,
+			},
+			&cli.IntFlag{
+				Name:     "warning-days",
+				Value:    cert.DefaultWarningDays,
+				Usage:    "warn when a certificate expires within this many day(s)",
+				Required: false,
+			},
+			&cli.IntFlag{
+				Name:     "critical-days",
+				Value:    cert.DefaultCriticalDays,
+				Usage:    "fail when a certificate expires within this many day(s)",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "only-failing",
+				Value:    false,
+				Usage:    "only report failed checks and certificates within the critical window",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "expiring-within",
+				Value:    "",
+				Usage:    "only report certificates expiring within this window (e.g., 30d, 72h)",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "issuer-match",
+				Value:    "",
+				Usage:    "only report certificates whose issuer matches this regular expression",
+				Required: false,
+			},
+			&cli.StringSliceFlag{
+				Name:     "tag",
+				Usage:    "only report hosts with this tag from the config file (can be repeated)",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "filter",
+				Value:    "",
+				Usage:    "only report results matching this expression (e.g., 'days_left < 14 && status != \"ok\"')",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "state-file",
+				Value:    "",
+				Usage:    "remember the certificates seen in this file to report rotations across runs",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "history-dir",
+				Value:    "",
+				Usage:    "record the results of every run in this directory, shown by the history command",
+				Required: false,
+			},
+			&cli.IntFlag{
+				Name:     "history-retention-days",
+				Value:    defaultHistoryRetentionDays,
+				Usage:    "prune recorded results older than this many day(s), 0 keeps them forever",
+				Required: false,
+			},
+			&cli.DurationFlag{
+				Name:     "watch",
+				Value:    0,
+				Usage:    "check the hosts again at this interval, reprinting the table with the changes highlighted (e.g., 1h)",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "schedule",
+				Value:    "",
+				Usage:    "check the hosts again on this cron schedule (e.g., '0 9 * * mon-fri', @daily)",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "stagger",
+				Value:    false,
+				Usage:    "spread the checks of the hosts across the first half of the watch interval",
 				Required: false,
 			},
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
-			cfg := &config.AppConfig{
-				ConfigFile:   c.String("config"),
-				Domains:      c.String("domains"),
-				Timeout:      c.Int("timeout"),
-				Insecure:     c.Bool("insecure"),
-				OutputFormat: c.String("output"),
-			}
+			cfg := newAppConfig(c)
 
-			// Create a context that can be cancelled by signals
-			ctx, cancel := context.WithCancel(ctx)
+			ctx, cancel := withSignalCancel(ctx)
 			defer cancel()
 
-			// Handle graceful shutdown
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-			go func() {
-				<-sigChan
-				cancel()
-			}()
-
 			application := app.New()
+			if cfg.Watching() {
+				if err := application.Watch(ctx, cfg); err != nil {
+					return exitWithError(err)
+				}
+				return nil
+			}
+
 			if err := application.Run(ctx, cfg); err != nil {
-				return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+				return exitWithError(err)
 			}
 
 			return nil
 		},
+		Commands: []*cli.Command{
+			{
+				Name:  "formats",
+				Usage: "list the supported output formats",
+				Action: func(ctx context.Context, c *cli.Command) error {
+					printFormats()
+					return nil
+				},
+			},
+			{
+				Name:  "history",
+				Usage: "show per-host timelines of certificate changes, failures and days left recorded with --history-dir",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:     "host",
+						Usage:    "only show the history of this host (can be repeated)",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "since",
+						Value:    "",
+						Usage:    "only show results recorded within this window (e.g., 30d, 12h)",
+						Required: false,
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					cfg := newAppConfig(c)
+					cfg.HistoryHosts = c.StringSlice("host")
+					cfg.HistorySince = c.String("since")
+
+					application := app.New()
+					if err := application.History(cfg); err != nil {
+						return exitWithError(err)
+					}
+
+					return nil
+				},
+			},
+			{
+				Name:      "diff",
+				Usage:     "compare a saved json/yaml result with another one or with a live run, exiting with 1 when anything changed",
+				ArgsUsage: "BASELINE [CURRENT]",
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.Args().Len() < 1 || c.Args().Len() > 2 {
+						return cli.Exit("Error: expected a baseline result file and an optional current result file", app.DiffExitTrouble)
+					}
+
+					cfg := newAppConfig(c)
+					cfg.DiffBaseline = c.Args().Get(0)
+					cfg.DiffCurrent = c.Args().Get(1)
+
+					ctx, cancel := withSignalCancel(ctx)
+					defer cancel()
+
+					application := app.New()
+					if err := application.Diff(ctx, cfg); err != nil {
+						return exitWithError(err)
+					}
+
+					return nil
+				},
+			},
+			{
+				Name:  "validate",
+				Usage: "check the config files and host lists without connecting, printing the effective hosts and every problem found",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:     "disallow-private",
+						Value:    false,
+						Usage:    "report localhost and private, loopback and link-local addresses",
+						Required: false,
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					cfg := newAppConfig(c)
+					cfg.DisallowPrivate = c.Bool("disallow-private")
+
+					application := app.New()
+					if err := application.Validate(cfg); err != nil {
+						return exitWithError(err)
+					}
+
+					return nil
+				},
+			},
+			{
+				Name:  "serve",
+				Usage: "run as a long-running exporter serving /metrics, /probe, /api/v1/results and /healthz",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "listen-address",
+						Aliases:  []string{"l"},
+						Value:    server.DefaultListenAddress,
+						Usage:    "address to listen on for HTTP requests",
+						Required: false,
+					},
+					&cli.DurationFlag{
+						Name:     "interval",
+						Aliases:  []string{"i"},
+						Value:    server.DefaultInterval,
+						Usage:    "interval between checks of the configured hosts",
+						Required: false,
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					cfg := newAppConfig(c)
+					cfg.ListenAddress = c.String("listen-address")
+					cfg.Interval = c.Duration("interval")
+
+					ctx, cancel := withSignalCancel(ctx)
+					defer cancel()
+
+					application := app.New()
+					if err := application.Serve(ctx, cfg); err != nil {
+						return exitWithError(err)
+					}
+
+					return nil
+				},
+			},
+		},
 	}
 
 	if err := cliApp.Run(context.Background(), os.Args); err != nil {
 		os.Exit(1)
 	}
 }
+
+// printFormats lists the registered output formats with their capabilities
+func printFormats() {
+	for _, format := range output.Formats() {
+		var capabilities []string
+		if format.Capabilities.Streaming {
+			capabilities = append(capabilities, "streaming")
+		}
+		if format.Capabilities.SupportsErrors {
+			capabilities = append(capabilities, "errors")
+		}
+		if format.Capabilities.Binary {
+			capabilities = append(capabilities, "binary")
+		}
+
+		line := fmt.Sprintf("%-12s %s", format.Name, format.Description)
+		if len(capabilities) > 0 {
+			line += fmt.Sprintf(" [%s]", strings.Join(capabilities, ", "))
+		}
+		fmt.Println(line)
+	}
+}
+
+// newAppConfig builds the application configuration from command line flags
+func newAppConfig(c *cli.Command) *config.AppConfig {
+	return &config.AppConfig{
+		ConfigFiles:  c.StringSlice("config"),
+		Domains:      c.String("domains"),
+		HostsFrom:    c.String("hosts-from"),
+		HostsCSV:     c.String("hosts-csv"),
+		HostColumn:   c.String("host-column"),
+		PortColumn:   c.String("port-column"),
+		TagColumns:   c.StringSlice("tag-columns"),
+		Timeout:      c.Int("timeout"),
+		Insecure:     c.Bool("insecure"),
+		OutputFormat: c.String("output"),
+		Template:     c.String("template"),
+		CheckFormat:  output.Check,
+		OutputFiles:  c.StringSlice("output-file"),
+		Textfile:     c.String("textfile"),
+		NoProgress:   c.Bool("no-progress"),
+		NoColor:      c.Bool("no-color"),
+		Compact:      c.Bool("compact"),
+		GroupBy:      c.String("group-by"),
+		TimeFormat:   c.String("time-format"),
+		Timezone:     c.String("timezone"),
+		WarningDays:  c.Int("warning-days"),
+		CriticalDays: c.Int("critical-days"),
+
+		OnlyFailing:    c.Bool("only-failing"),
+		ExpiringWithin: c.String("expiring-within"),
+		IssuerMatch:    c.String("issuer-match"),
+		Tags:           c.StringSlice("tag"),
+		Filter:         c.String("filter"),
+
+		StateFile: c.String("state-file"),
+
+		HistoryDir:           c.String("history-dir"),
+		HistoryRetentionDays: c.Int("history-retention-days"),
+
+		Watch:    c.Duration("watch"),
+		Schedule: c.String("schedule"),
+		Stagger:  c.Bool("stagger"),
+	}
+}
+
+// exitWithError converts an application error into a CLI exit error,
+// preserving the exit code requested by the application
+func exitWithError(err error) error {
+	var exitErr *app.ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.Err == nil {
+			return cli.Exit("", exitErr.Code)
+		}
+		return cli.Exit(fmt.Sprintf("Error: %v", exitErr.Err), exitErr.Code)
+	}
+
+	return cli.Exit(fmt.Sprintf("Error: %v", err), 1)
+}
+
+// withSignalCancel returns a context that is cancelled on SIGINT or SIGTERM
+func withSignalCancel(ctx context.Context) (context.Context, context.CancelFunc) {
+	// Create a context that can be cancelled by signals
+	ctx, cancel := context.WithCancel(ctx)
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigChan)
+	}()
+
+	return ctx, cancel
+}
@@ -3,11 +3,19 @@ package app
 import (
 	"context"
 	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sync/atomic"
 	"time"
 
 	"github.com/guessi/ssl-certs-checker/pkg/cert"
 	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/filter"
+	"github.com/guessi/ssl-certs-checker/pkg/notify"
 	"github.com/guessi/ssl-certs-checker/pkg/output"
+	"github.com/guessi/ssl-certs-checker/pkg/rotation"
+	"github.com/guessi/ssl-certs-checker/pkg/server"
 )
 
 // New creates a new application instance
@@ -19,26 +27,374 @@ func New() *App {
 
 // Run executes the application with the given configuration
 func (a *App) Run(ctx context.Context, cfg *config.AppConfig) error {
-	if err := cfg.Validate(); err != nil {
-		return fmt.Errorf("configuration validation failed: %w", err)
+	result, err := a.run(ctx, cfg)
+
+	if cfg.OutputFormat != "nagios" {
+		return err
+	}
+
+	// Nagios plugins report through the status line and exit code only
+	if err != nil {
+		output.WriteNagiosUnknown(os.Stdout, err)
+		return &ExitError{Code: output.NagiosUnknown, Err: err}
+	}
+
+	if code := a.formatter.NagiosExitCode(result); code != output.NagiosOK {
+		return &ExitError{Code: code}
+	}
+
+	return nil
+}
+
+// run checks the configured hosts and writes the formatted results
+func (a *App) run(ctx context.Context, cfg *config.AppConfig) (*cert.Result, error) {
+	if err := a.validate(cfg, cfg.Validate); err != nil {
+		return nil, err
+	}
+
+	hostConfig, err := cfg.GetHostConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hosts: %w", err)
+	}
+
+	s, err := a.newSession(cfg, hostConfig, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	checked, result, err := a.checkAndFormat(ctx, cfg, hostConfig, s.filter, s.tracker)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.finish(ctx, cfg, s, checked, result); err != nil {
+		return nil, err
+	}
+
+	// Email reports are only sent by single runs, they show the same hosts
+	// as the output
+	for _, mailer := range s.mailers {
+		if err := mailer.Send(ctx, result); err != nil {
+			return nil, fmt.Errorf("failed to send email report: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// newSession prepares the checker, the formatter and everything kept across
+// the runs of a host configuration. The alerter of the previous session is
+// carried over when the alerts configuration did not change, so alerts kept
+// in memory are not sent again.
+func (a *App) newSession(cfg *config.AppConfig, hostConfig *config.Config, previous *session) (*session, error) {
+	resultFilter, err := newFilter(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get filter: %w", err)
+	}
+
+	tmpl, err := cfg.GetTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	location, err := cfg.GetLocation()
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := newHistoryStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+
+	tracker, err := newRotationTracker(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+
+	alerts, err := loadAlerts(hostConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up alerts: %w", err)
+	}
+
+	thresholds := cert.Thresholds{
+		WarningDays:  cfg.WarningDays,
+		CriticalDays: cfg.CriticalDays,
+	}
+	registry := a.formatter.Registry()
+
+	s := &session{
+		hostConfig: hostConfig,
+		filter:     resultFilter,
+		thresholds: thresholds,
+		store:      store,
+		tracker:    tracker,
+		alerts:     alerts,
+		options: output.Options{
+			Thresholds: thresholds,
+			Template:   tmpl,
+			Color:      !cfg.NoColor && output.IsTerminal(os.Stdout),
+			TimeFormat: cfg.TimeFormat,
+			Location:   location,
+			Compact:    cfg.Compact,
+			GroupBy:    cfg.GroupBy,
+			Registry:   registry,
+		},
+	}
+
+	if alerts != nil {
+		if previous != nil && previous.alerter != nil && reflect.DeepEqual(previous.alerts, alerts) {
+			s.alerter = previous.alerter
+		} else if s.alerter, err = notify.New(*alerts, thresholds); err != nil {
+			return nil, fmt.Errorf("failed to set up alerts: %w", err)
+		}
+
+		reportFormatter := output.NewWithOptions(output.Options{
+			Thresholds: thresholds,
+			TimeFormat: cfg.TimeFormat,
+			Location:   location,
+			Compact:    cfg.Compact,
+			Registry:   registry,
+		})
+		for _, emailConfig := range alerts.Email {
+			mailer, err := notify.NewMailer(emailConfig, reportFormatter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set up email report: %w", err)
+			}
+			s.mailers = append(s.mailers, mailer)
+		}
+	}
+
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	a.checker = cert.New(timeout, cfg.Insecure)
+	a.checker.SetTags(hostConfig.Tags)
+	a.checker.SetSources(hostConfig.HostSources())
+	a.checker.SetLabels(hostConfig.Labels)
+	a.formatter = output.NewWithOptions(s.options)
+
+	if cfg.OutputFormat == "template" {
+		if err := a.formatter.CheckTemplate(); err != nil {
+			return nil, fmt.Errorf("failed to parse template: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// finish writes the output files, records the results and sends the alerts
+// of a run
+func (a *App) finish(ctx context.Context, cfg *config.AppConfig, s *session, checked, result *cert.Result) error {
+	targets, err := outputTargets(cfg, a.formatter.Registry())
+	if err != nil {
+		return fmt.Errorf("failed to get output files: %w", err)
 	}
 
-	hosts, err := cfg.GetHosts()
+	for _, target := range targets {
+		if err := a.formatter.WriteFile(target.Path, result, target.Format); err != nil {
+			return fmt.Errorf("failed to write %s output to %s: %w", target.Format, target.Path, err)
+		}
+	}
+
+	// Metrics cover every host, a filtered host would look like a vanished one
+	if cfg.Textfile != "" {
+		if err := a.formatter.WriteTextfile(cfg.Textfile, checked); err != nil {
+			return fmt.Errorf("failed to write textfile: %w", err)
+		}
+	}
+
+	// The history records every host, regardless of the filter
+	if s.store != nil {
+		if err := s.store.Append(checked, s.thresholds); err != nil {
+			return fmt.Errorf("failed to record history: %w", err)
+		}
+	}
+
+	if s.tracker != nil {
+		if err := s.tracker.Save(); err != nil {
+			return fmt.Errorf("failed to save state: %w", err)
+		}
+	}
+
+	// Alerts are about every host as well, filters only shape the output
+	if s.alerter != nil {
+		if err := s.alerter.Process(ctx, checked); err != nil {
+			return fmt.Errorf("failed to send alerts: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checkAndFormat checks the hosts and writes the results passing the filter,
+// streaming them as the checks complete when the output format supports it.
+// Rotations are detected when a tracker is given. It returns the results of
+// all hosts along with the filtered results.
+func (a *App) checkAndFormat(ctx context.Context, cfg *config.AppConfig, hostConfig *config.Config, resultFilter *filter.Filter, tracker *rotation.Tracker) (*cert.Result, *cert.Result, error) {
+	format := cfg.OutputFormat
+	hosts := hostConfig.Hosts
+
+	if f, ok := a.formatter.Lookup(format); !ok || !f.Capabilities.Streaming {
+		var onComplete func(cert.Outcome)
+
+		progress := newProgress(cfg, len(hosts))
+		if progress != nil {
+			a.checker.OnStart(progress.Started)
+			onComplete = progress.Completed
+			progress.Start()
+		}
+
+		result, err := a.checker.CheckCertificatesFunc(ctx, hosts, onComplete)
+
+		if progress != nil {
+			progress.Stop()
+		}
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check certificates: %w", err)
+		}
+
+		result.ConfigVersion = hostConfig.Version
+
+		if tracker != nil {
+			tracker.ObserveResult(result)
+		}
+
+		filtered := resultFilter.Apply(result)
+
+		if err := a.formatter.Format(filtered, format); err != nil {
+			return nil, nil, fmt.Errorf("failed to format output: %w", err)
+		}
+
+		return result, filtered, nil
+	}
+
+	stream, err := a.formatter.NewStream(format)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format output: %w", err)
+	}
+
+	var streamErr error
+	var rotations []cert.Rotation
+	result, err := a.checker.CheckCertificatesFunc(ctx, hosts, func(outcome cert.Outcome) {
+		if tracker != nil && outcome.Certificate != nil {
+			if outcome.Rotation = tracker.Observe(*outcome.Certificate); outcome.Rotation != nil {
+				rotations = append(rotations, *outcome.Rotation)
+			}
+		}
+
+		if streamErr == nil && resultFilter.Match(outcome) {
+			streamErr = stream.Write(outcome)
+		}
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check certificates: %w", err)
+	}
+
+	result.Rotations = rotations
+	result.ConfigVersion = hostConfig.Version
+
+	if streamErr != nil {
+		return nil, nil, fmt.Errorf("failed to format output: %w", streamErr)
+	}
+
+	filtered := resultFilter.Apply(result)
+
+	if err := stream.Close(filtered); err != nil {
+		return nil, nil, fmt.Errorf("failed to format output: %w", err)
+	}
+
+	return result, filtered, nil
+}
+
+// newProgress returns a live progress display for interactive table runs,
+// nil when the output is not a terminal or meant for machines
+func newProgress(cfg *config.AppConfig, total int) *output.Progress {
+	if cfg.NoProgress {
+		return nil
+	}
+
+	if cfg.OutputFormat != "" && cfg.OutputFormat != output.DefaultFormat {
+		return nil
+	}
+
+	if !output.IsTerminal(os.Stdout) || !output.IsTerminal(os.Stderr) {
+		return nil
+	}
+
+	return output.NewProgress(os.Stderr, total)
+}
+
+// Serve runs the long-running exporter with the given configuration until
+// the context is cancelled. The config file is reloaded on SIGHUP and when it
+// is modified, a config file failing to load keeps the previous one.
+func (a *App) Serve(ctx context.Context, cfg *config.AppConfig) error {
+	if err := a.validate(cfg, cfg.ValidateServe); err != nil {
+		return err
+	}
+
+	hostConfig, err := cfg.GetHostConfig()
 	if err != nil {
 		return fmt.Errorf("failed to get hosts: %w", err)
 	}
 
 	timeout := time.Duration(cfg.Timeout) * time.Second
 	a.checker = cert.New(timeout, cfg.Insecure)
+	a.checker.SetTags(hostConfig.Tags)
+	a.checker.SetSources(hostConfig.HostSources())
+	a.checker.SetLabels(hostConfig.Labels)
 
-	result, err := a.checker.CheckCertificates(ctx, hosts)
+	exporter := server.New(a.checker, hostConfig.Hosts, cfg.Interval)
+	exporter.SetConfigVersion(hostConfig.Version)
+
+	alerts, err := loadAlerts(hostConfig)
 	if err != nil {
-		return fmt.Errorf("failed to check certificates: %w", err)
+		return fmt.Errorf("failed to set up alerts: %w", err)
+	}
+	warnEmailIgnored(alerts)
+
+	// The alerter is replaced when the config file is reloaded
+	var alerter atomic.Pointer[notify.Alerter]
+	if alerts != nil {
+		thresholds := cert.Thresholds{WarningDays: cfg.WarningDays, CriticalDays: cfg.CriticalDays}
+		initial, err := notify.New(*alerts, thresholds)
+		if err != nil {
+			return fmt.Errorf("failed to set up alerts: %w", err)
+		}
+		alerter.Store(initial)
 	}
+	exporter.OnResult(func(ctx context.Context, result *cert.Result) {
+		current := alerter.Load()
+		if current == nil {
+			return
+		}
+		if err := current.Process(ctx, result); err != nil {
+			log.Printf("failed to send alerts: %v", err)
+		}
+	})
+
+	go reloadServer(ctx, cfg, hostConfig, alerts, exporter, &alerter)
 
-	if err := a.formatter.Format(result, cfg.OutputFormat); err != nil {
-		return fmt.Errorf("failed to format output: %w", err)
+	if err := exporter.Run(ctx, cfg.ListenAddress); err != nil {
+		return fmt.Errorf("exporter failed: %w", err)
 	}
 
 	return nil
 }
+
+// Error returns the message of the underlying error
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
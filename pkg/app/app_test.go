@@ -2,11 +2,21 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
 	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/internal/tlstest"
+	"github.com/guessi/ssl-certs-checker/pkg/output"
 )
 
 func TestNew(t *testing.T) {
@@ -83,7 +93,7 @@ func TestApp_Run_ValidConfigWithFile(t *testing.T) {
 
 	// Test with valid config using config file
 	cfg := &config.AppConfig{
-		ConfigFile:   configPath,
+		ConfigFiles:  []string{configPath},
 		Timeout:      10,
 		OutputFormat: "table",
 	}
@@ -100,7 +110,7 @@ func TestApp_Run_NonExistentConfigFile(t *testing.T) {
 
 	// Test with non-existent config file
 	cfg := &config.AppConfig{
-		ConfigFile:   "/non/existent/file.yaml",
+		ConfigFiles:  []string{"/non/existent/file.yaml"},
 		Timeout:      5,
 		OutputFormat: "table",
 	}
@@ -147,3 +157,247 @@ func TestApp_Run_ContextCancellation(t *testing.T) {
 		t.Error("Run() should return error for cancelled context")
 	}
 }
+
+func TestApp_Run_NagiosExitCodes(t *testing.T) {
+	app := New()
+	ctx := context.Background()
+
+	// Configuration problems are reported as UNKNOWN
+	cfg := &config.AppConfig{
+		Timeout:      5,
+		OutputFormat: "nagios",
+	}
+
+	err := app.Run(ctx, cfg)
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Run() error = %v, want *ExitError", err)
+	}
+	if exitErr.ExitCode() != output.NagiosUnknown {
+		t.Errorf("Run() exit code = %d, want %d", exitErr.ExitCode(), output.NagiosUnknown)
+	}
+
+	// Unreachable hosts are reported as CRITICAL
+	cfg = &config.AppConfig{
+		Domains:      "invalid::domain,127.0.0.1:1",
+		Timeout:      5,
+		OutputFormat: "nagios",
+	}
+
+	err = app.Run(ctx, cfg)
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Run() error = %v, want *ExitError", err)
+	}
+	if exitErr.ExitCode() != output.NagiosCritical {
+		t.Errorf("Run() exit code = %d, want %d", exitErr.ExitCode(), output.NagiosCritical)
+	}
+	if exitErr.Err != nil {
+		t.Errorf("Run() should not report an error for a completed check: %v", exitErr.Err)
+	}
+}
+
+func TestApp_Run_OutputFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	jsonPath := filepath.Join(tempDir, "report.json")
+	promPath := filepath.Join(tempDir, "metrics.txt")
+
+	app := New()
+	cfg := &config.AppConfig{
+		Domains:      "127.0.0.1:1",
+		Timeout:      5,
+		OutputFormat: "table",
+		OutputFiles:  []string{jsonPath, "prometheus=" + promPath},
+	}
+
+	if err := app.Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Run() did not write the JSON output: %v", err)
+	}
+	if !strings.Contains(string(data), `"host": "127.0.0.1:1"`) {
+		t.Errorf("JSON output missing host:\n%s", data)
+	}
+
+	data, err = os.ReadFile(promPath)
+	if err != nil {
+		t.Fatalf("Run() did not write the Prometheus output: %v", err)
+	}
+	if !strings.Contains(string(data), `ssl_cert_check_success{host="127.0.0.1:1"} 0`) {
+		t.Errorf("Prometheus output missing host:\n%s", data)
+	}
+}
+
+func TestApp_Run_Filter(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "hosts.yaml")
+	jsonPath := filepath.Join(tempDir, "report.json")
+	textfilePath := filepath.Join(tempDir, "certs.prom")
+
+	configContent := `hosts:
+  - 127.0.0.1:1
+  - 127.0.0.1:2
+tags:
+  127.0.0.1:1: [production]
+  127.0.0.1:2: [staging]
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	for _, format := range []string{"table", "ndjson"} {
+		t.Run(format, func(t *testing.T) {
+			app := New()
+			cfg := &config.AppConfig{
+				ConfigFiles:  []string{configPath},
+				Timeout:      5,
+				OutputFormat: format,
+				OutputFiles:  []string{jsonPath},
+				Textfile:     textfilePath,
+				Tags:         []string{"production"},
+				Filter:       `kind == "connection"`,
+			}
+
+			if err := app.Run(context.Background(), cfg); err != nil {
+				t.Fatalf("Run() unexpected error: %v", err)
+			}
+
+			data, err := os.ReadFile(jsonPath)
+			if err != nil {
+				t.Fatalf("Run() did not write the JSON output: %v", err)
+			}
+			if !strings.Contains(string(data), `"host": "127.0.0.1:1"`) || !strings.Contains(string(data), `"production"`) {
+				t.Errorf("JSON output missing tagged host:\n%s", data)
+			}
+			if strings.Contains(string(data), "127.0.0.1:2") {
+				t.Errorf("JSON output should not contain filtered host:\n%s", data)
+			}
+
+			// The textfile covers every host, regardless of the filter
+			data, err = os.ReadFile(textfilePath)
+			if err != nil {
+				t.Fatalf("Run() did not write the textfile: %v", err)
+			}
+			if !strings.Contains(string(data), `host="127.0.0.1:2"`) {
+				t.Errorf("textfile missing filtered host:\n%s", data)
+			}
+		})
+	}
+
+	cfg := &config.AppConfig{
+		ConfigFiles: []string{configPath},
+		Timeout:     5,
+		Filter:      "days_left <",
+	}
+	if err := New().Run(context.Background(), cfg); err == nil {
+		t.Error("Run() should return error for an invalid filter expression")
+	}
+}
+
+func TestApp_Run_Rotations(t *testing.T) {
+	host := tlstest.StartServer(t, time.Now().Add(-time.Hour), time.Now().AddDate(0, 1, 0))
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	jsonPath := filepath.Join(t.TempDir(), "report.json")
+
+	// The host served another certificate before
+	state := `{"version": 1, "certificates": {"` + host + `|127.0.0.1": {"host": "` + host + `", "server_name": "127.0.0.1", "serial_number": "01", "fingerprint_sha256": "AA"}}}`
+	if err := os.WriteFile(statePath, []byte(state), 0644); err != nil {
+		t.Fatalf("Failed to write state file: %v", err)
+	}
+
+	for _, format := range []string{"ndjson", "json"} {
+		t.Run(format, func(t *testing.T) {
+			cfg := &config.AppConfig{
+				Domains:      host,
+				Timeout:      5,
+				Insecure:     true,
+				OutputFormat: format,
+				OutputFiles:  []string{jsonPath},
+				StateFile:    statePath,
+			}
+
+			if err := New().Run(context.Background(), cfg); err != nil {
+				t.Fatalf("Run() unexpected error: %v", err)
+			}
+
+			data, err := os.ReadFile(jsonPath)
+			if err != nil {
+				t.Fatalf("Run() did not write the JSON output: %v", err)
+			}
+
+			var result cert.Result
+			if err := json.Unmarshal(data, &result); err != nil {
+				t.Fatalf("Run() wrote invalid JSON: %v", err)
+			}
+
+			// Only the first run sees the rotation, the state is updated afterwards
+			wantRotations := 0
+			if format == "ndjson" {
+				wantRotations = 1
+			}
+			if len(result.Rotations) != wantRotations {
+				t.Fatalf("Run() rotations = %+v, want %d", result.Rotations, wantRotations)
+			}
+			if wantRotations == 1 && (result.Rotations[0].Old.SerialNumber != "01" || result.Rotations[0].New.SerialNumber != "1092") {
+				t.Errorf("Run() rotation = %+v", result.Rotations[0])
+			}
+		})
+	}
+}
+
+func TestApp_Run_Alerts(t *testing.T) {
+	host := tlstest.StartServer(t, time.Now().Add(-time.Hour), time.Now().AddDate(0, 0, 10))
+
+	var requests []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, string(body))
+	}))
+	defer receiver.Close()
+
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "hosts.yaml")
+	content := "hosts:\n  - " + host + "\n  - 127.0.0.1:1\nalerts:\n  state_file: " + filepath.Join(tempDir, "alerts.json") +
+		"\n  webhooks:\n    - type: slack\n      url: " + receiver.URL +
+		"\n  email:\n    - from: certs@example.com\n      recipients: [ops@example.com]\n      dry_run: " + filepath.Join(tempDir, "outbox") + "\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg := &config.AppConfig{
+		ConfigFiles:  []string{configPath},
+		Timeout:      5,
+		Insecure:     true,
+		OutputFormat: "json",
+		OutputFiles:  []string{filepath.Join(tempDir, "report.json")},
+		WarningDays:  30,
+		CriticalDays: 7,
+		NoProgress:   true,
+	}
+
+	for run := 0; run < 2; run++ {
+		if err := New().Run(context.Background(), cfg); err != nil {
+			t.Fatalf("Run() unexpected error: %v", err)
+		}
+	}
+
+	// The second run has nothing new to report
+	if len(requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1: %v", len(requests), requests)
+	}
+	if !strings.Contains(requests[0], "[WARNING] "+host) || !strings.Contains(requests[0], "[ERROR] 127.0.0.1:1") {
+		t.Errorf("receiver got unexpected alert: %s", requests[0])
+	}
+
+	// The email report is sent on every run
+	message, err := os.ReadFile(filepath.Join(tempDir, "outbox", "email-ops@example.com.eml"))
+	if err != nil {
+		t.Fatalf("Run() did not write the email report: %v", err)
+	}
+	if !strings.Contains(string(message), "Subject: SSL certificate report: 0 ok, 1 warning, 0 critical, 1 error(s)") {
+		t.Errorf("Run() wrote unexpected email report:\n%s", message)
+	}
+}
@@ -1,11 +1,63 @@
 package app
 
 import (
+	"io"
+
 	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/filter"
+	"github.com/guessi/ssl-certs-checker/pkg/history"
+	"github.com/guessi/ssl-certs-checker/pkg/notify"
 	"github.com/guessi/ssl-certs-checker/pkg/output"
+	"github.com/guessi/ssl-certs-checker/pkg/rotation"
 )
 
 type App struct {
 	checker   *cert.Checker
 	formatter *output.Formatter
+	// out receives the output of the watch mode, os.Stdout when nil
+	out io.Writer
+}
+
+// ExitError carries the process exit code a run should terminate with,
+// optionally along with the error that caused it
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// session holds what the runs of a host configuration share, from a single
+// run to the iterations of the watch mode
+type session struct {
+	hostConfig *config.Config
+	filter     *filter.Filter
+	thresholds cert.Thresholds
+	options    output.Options
+	store      *history.Store
+	tracker    *rotation.Tracker
+	alerts     *notify.Config
+	alerter    *notify.Alerter
+	mailers    []*notify.Mailer
+}
+
+// fileStamp identifies a version of a file
+type fileStamp struct {
+	path    string
+	modTime int64
+	size    int64
+}
+
+// lintDocument is the JSON document of the validate command
+type lintDocument struct {
+	Hosts    []lintHost `json:"hosts"`
+	Problems []string   `json:"problems"`
+}
+
+// lintHost is a host of the effective host list in the JSON document of the
+// validate command
+type lintHost struct {
+	Host   string            `json:"host"`
+	Source string            `json:"source,omitempty"`
+	Tags   []string          `json:"tags,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
 }
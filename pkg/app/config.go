@@ -0,0 +1,169 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/filter"
+	"github.com/guessi/ssl-certs-checker/pkg/history"
+	"github.com/guessi/ssl-certs-checker/pkg/notify"
+	"github.com/guessi/ssl-certs-checker/pkg/output"
+	"github.com/guessi/ssl-certs-checker/pkg/rotation"
+	"github.com/guessi/ssl-certs-checker/pkg/schedule"
+)
+
+// validate checks the configuration with the validator of the command, then
+// the settings only the feature packages can check: the output formats, the
+// output files and the filter
+func (a *App) validate(cfg *config.AppConfig, validator func() error) error {
+	if err := validator(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	if err := validateOutput(cfg, a.formatter.Registry(), output.IsTerminal(a.stdout())); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	if _, err := newFilter(cfg); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	return nil
+}
+
+// validateOutput checks that the output format and the formats of the output
+// files are registered, and that a template is given when one of them needs
+// it. Binary formats are refused when the output is a terminal.
+func validateOutput(cfg *config.AppConfig, registry *output.Registry, terminal bool) error {
+	if cfg.OutputFormat != "" {
+		if err := registry.Check(cfg.OutputFormat); err != nil {
+			return err
+		}
+		if format, _ := registry.Lookup(cfg.OutputFormat); format.Capabilities.Binary && terminal {
+			return fmt.Errorf("output format %s is binary and not meant for terminals, redirect the output or use --output-file", cfg.OutputFormat)
+		}
+	}
+
+	targets, err := outputTargets(cfg, registry)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Template == "" {
+		if cfg.OutputFormat == "template" {
+			return fmt.Errorf("--template must be specified with the template output format")
+		}
+		for _, target := range targets {
+			if target.Format == "template" {
+				return fmt.Errorf("--template must be specified with the template output format (%s)", target.Path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// outputTargets returns the additional files the results are written to
+func outputTargets(cfg *config.AppConfig, registry *output.Registry) ([]output.Target, error) {
+	targets := make([]output.Target, 0, len(cfg.OutputFiles))
+	for _, spec := range cfg.OutputFiles {
+		target, err := registry.ParseTarget(spec, cfg.OutputFormat)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// newFilter returns the filter applied to the results before formatting
+func newFilter(cfg *config.AppConfig) (*filter.Filter, error) {
+	var window time.Duration
+	if cfg.ExpiringWithin != "" {
+		var err error
+		if window, err = filter.ParseWindow(cfg.ExpiringWithin); err != nil {
+			return nil, err
+		}
+	}
+
+	return filter.New(filter.Options{
+		OnlyFailing:    cfg.OnlyFailing,
+		ExpiringWithin: window,
+		IssuerMatch:    cfg.IssuerMatch,
+		Tags:           cfg.Tags,
+		Expression:     cfg.Filter,
+		Thresholds: cert.Thresholds{
+			WarningDays:  cfg.WarningDays,
+			CriticalDays: cfg.CriticalDays,
+		},
+	})
+}
+
+// newSchedule returns the parsed --schedule cron expression, nil when the
+// hosts are checked at a fixed interval
+func newSchedule(cfg *config.AppConfig) (*schedule.Schedule, error) {
+	if cfg.Schedule == "" {
+		return nil, nil
+	}
+	return schedule.Parse(cfg.Schedule)
+}
+
+// newHistoryStore returns the history store, nil when no history directory
+// is configured
+func newHistoryStore(cfg *config.AppConfig) (*history.Store, error) {
+	if cfg.HistoryDir == "" {
+		return nil, nil
+	}
+
+	retention := time.Duration(cfg.HistoryRetentionDays) * 24 * time.Hour
+	return history.New(cfg.HistoryDir, retention)
+}
+
+// newRotationTracker returns the tracker detecting certificate rotations
+// across runs, nil when no state file is configured
+func newRotationTracker(cfg *config.AppConfig) (*rotation.Tracker, error) {
+	if cfg.StateFile == "" {
+		return nil, nil
+	}
+	return rotation.Load(cfg.StateFile)
+}
+
+// historySince returns the start of the history shown by the history
+// command, the zero time to show all of it
+func historySince(cfg *config.AppConfig, now time.Time) (time.Time, error) {
+	if cfg.HistorySince == "" {
+		return time.Time{}, nil
+	}
+
+	window, err := filter.ParseWindow(cfg.HistorySince)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return now.Add(-window), nil
+}
+
+// loadAlerts decodes the alerts of the host configuration and resolves their
+// secrets, nil when no config file defines alerts
+func loadAlerts(hostConfig *config.Config) (*notify.Config, error) {
+	if !hostConfig.HasAlerts() {
+		return nil, nil
+	}
+
+	alerts := &notify.Config{}
+	if err := hostConfig.DecodeAlerts(alerts); err != nil {
+		return nil, err
+	}
+
+	if err := alerts.ResolveSecrets(config.ResolveSecret); err != nil {
+		return nil, fmt.Errorf("invalid secret in %s: %w", hostConfig.AlertsFile, err)
+	}
+
+	if err := alerts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid alerts configuration in %s: %w", hostConfig.AlertsFile, err)
+	}
+
+	return alerts, nil
+}
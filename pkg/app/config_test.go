@@ -0,0 +1,230 @@
+package app
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/output"
+)
+
+func TestApp_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.AppConfig
+		wantErr bool
+	}{
+		{
+			name: "valid config",
+			cfg:  config.AppConfig{Domains: "example.com", Timeout: 5, OutputFiles: []string{"report.json"}},
+		},
+		{
+			name:    "invalid settings",
+			cfg:     config.AppConfig{Domains: "example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid output format",
+			cfg:     config.AppConfig{Domains: "example.com", Timeout: 5, OutputFormat: "xml"},
+			wantErr: true,
+		},
+		{
+			name:    "output file with template format but no template",
+			cfg:     config.AppConfig{Domains: "example.com", Timeout: 5, OutputFormat: "table", OutputFiles: []string{"template=report.txt"}},
+			wantErr: true,
+		},
+		{
+			name:    "template output without template",
+			cfg:     config.AppConfig{Domains: "example.com", Timeout: 5, OutputFormat: "template"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid expiry window",
+			cfg:     config.AppConfig{Domains: "example.com", Timeout: 5, ExpiringWithin: "a month"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid filter expression",
+			cfg:     config.AppConfig{Domains: "example.com", Timeout: 5, Filter: "days_left <"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().validate(&tt.cfg, tt.cfg.Validate)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "configuration validation failed") {
+					t.Errorf("validate() error = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestApp_validate_CustomRegistry(t *testing.T) {
+	registry := output.NewRegistry()
+	err := registry.Register(output.Format{
+		Name:       "count",
+		Extensions: []string{".count"},
+		Render: func(f *output.Formatter, w io.Writer, result *cert.Result) error {
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+
+	a := &App{formatter: output.NewWithOptions(output.Options{Registry: registry})}
+	cfg := config.AppConfig{Domains: "example.com", Timeout: 5, OutputFormat: "count", OutputFiles: []string{"hosts.count"}}
+	if err := a.validate(&cfg, cfg.Validate); err != nil {
+		t.Errorf("validate() unexpected error for a format of the registry: %v", err)
+	}
+
+	cfg.OutputFormat = "json"
+	if err := a.validate(&cfg, cfg.Validate); err == nil {
+		t.Error("validate() expected error for a format of another registry")
+	}
+}
+
+func TestValidateOutput_Binary(t *testing.T) {
+	registry := output.NewRegistry()
+	err := registry.Register(output.Format{
+		Name:         "archive",
+		Capabilities: output.Capabilities{Binary: true},
+		Render: func(f *output.Formatter, w io.Writer, result *cert.Result) error {
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+
+	cfg := &config.AppConfig{OutputFormat: "archive"}
+	if err := validateOutput(cfg, registry, true); err == nil {
+		t.Error("validateOutput() expected error for a binary format on a terminal")
+	}
+	if err := validateOutput(cfg, registry, false); err != nil {
+		t.Errorf("validateOutput() unexpected error for redirected output: %v", err)
+	}
+}
+
+func TestNewSchedule(t *testing.T) {
+	sched, err := newSchedule(&config.AppConfig{})
+	if err != nil || sched != nil {
+		t.Errorf("newSchedule() = %v, %v, want no schedule", sched, err)
+	}
+
+	if sched, err = newSchedule(&config.AppConfig{Schedule: "0 9 * * 1-5"}); err != nil || sched == nil {
+		t.Errorf("newSchedule() = %v, %v, want a schedule", sched, err)
+	}
+
+	if _, err := newSchedule(&config.AppConfig{Schedule: "every day"}); err == nil {
+		t.Error("newSchedule() expected error but got none")
+	}
+}
+
+func TestHistorySince(t *testing.T) {
+	now := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	since, err := historySince(&config.AppConfig{}, now)
+	if err != nil || !since.IsZero() {
+		t.Errorf("historySince() = %v, %v, want the zero time", since, err)
+	}
+
+	since, err = historySince(&config.AppConfig{HistorySince: "7d"}, now)
+	if err != nil || !since.Equal(now.AddDate(0, 0, -7)) {
+		t.Errorf("historySince() = %v, %v, want %v", since, err, now.AddDate(0, 0, -7))
+	}
+
+	if _, err := historySince(&config.AppConfig{HistorySince: "a while"}, now); err == nil {
+		t.Error("historySince() expected error but got none")
+	}
+}
+
+func TestNewHistoryStore(t *testing.T) {
+	store, err := newHistoryStore(&config.AppConfig{})
+	if err != nil || store != nil {
+		t.Errorf("newHistoryStore() = %v, %v, want no store", store, err)
+	}
+
+	store, err = newHistoryStore(&config.AppConfig{HistoryDir: t.TempDir(), HistoryRetentionDays: 90})
+	if err != nil || store == nil {
+		t.Errorf("newHistoryStore() = %v, %v, want a store", store, err)
+	}
+}
+
+func TestLoadAlerts(t *testing.T) {
+	t.Setenv("CHECKER_WEBHOOK", "https://hooks.slack.com/services/T000/B000/XXX")
+
+	tests := []struct {
+		name    string
+		content string
+		wantURL string
+		wantErr string
+	}{
+		{
+			name:    "no alerts",
+			content: "hosts:\n  - example.com\n",
+		},
+		{
+			name:    "secret reference",
+			content: "hosts:\n  - example.com\nalerts:\n  webhooks:\n    - type: slack\n      url: env:CHECKER_WEBHOOK\n",
+			wantURL: "https://hooks.slack.com/services/T000/B000/XXX",
+		},
+		{
+			name:    "unset secret",
+			content: "hosts:\n  - example.com\nalerts:\n  webhooks:\n    - type: slack\n      url: env:CHECKER_UNSET\n",
+			wantErr: "invalid secret in",
+		},
+		{
+			name:    "unknown webhook type",
+			content: "hosts:\n  - example.com\nalerts:\n  webhooks:\n    - type: irc\n      url: https://example.com\n",
+			wantErr: "invalid alerts configuration in",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "hosts.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			hostConfig, err := config.LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig() unexpected error: %v", err)
+			}
+
+			alerts, err := loadAlerts(hostConfig)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("loadAlerts() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadAlerts() unexpected error: %v", err)
+			}
+
+			if tt.wantURL == "" {
+				if alerts != nil {
+					t.Errorf("loadAlerts() = %+v, want no alerts", alerts)
+				}
+				return
+			}
+			if alerts == nil || len(alerts.Webhooks) != 1 || alerts.Webhooks[0].URL != tt.wantURL {
+				t.Errorf("loadAlerts() = %+v, want the resolved webhook URL", alerts)
+			}
+		})
+	}
+}
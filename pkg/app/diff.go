@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/diff"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+const (
+	DiffExitChanged = 1
+	DiffExitTrouble = 2
+)
+
+// Diff compares a baseline result with a saved result or a live run and
+// prints the changes. Like diff(1), it exits with 1 when anything changed
+// and with 2 when the comparison failed.
+func (a *App) Diff(ctx context.Context, cfg *config.AppConfig) error {
+	report, err := a.diff(ctx, cfg)
+	if err != nil {
+		return &ExitError{Code: DiffExitTrouble, Err: err}
+	}
+
+	if report.Changed() {
+		return &ExitError{Code: DiffExitChanged}
+	}
+
+	return nil
+}
+
+// diff compares the results and writes the report
+func (a *App) diff(ctx context.Context, cfg *config.AppConfig) (*diff.Report, error) {
+	if err := cfg.ValidateDiff(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	resultFilter, err := newFilter(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	base, err := diff.Load(cfg.DiffBaseline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load baseline: %w", err)
+	}
+
+	var current *cert.Result
+	if cfg.DiffCurrent != "" {
+		if current, err = diff.Load(cfg.DiffCurrent); err != nil {
+			return nil, fmt.Errorf("failed to load result: %w", err)
+		}
+	} else {
+		hostConfig, err := cfg.GetHostConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hosts: %w", err)
+		}
+
+		a.checker = cert.New(time.Duration(cfg.Timeout)*time.Second, cfg.Insecure)
+		a.checker.SetTags(hostConfig.Tags)
+		a.checker.SetSources(hostConfig.HostSources())
+		a.checker.SetLabels(hostConfig.Labels)
+
+		if current, err = a.checker.CheckCertificates(ctx, hostConfig.Hosts); err != nil {
+			return nil, fmt.Errorf("failed to check certificates: %w", err)
+		}
+	}
+
+	report := diff.Compare(resultFilter.Apply(base), resultFilter.Apply(current))
+
+	if cfg.OutputFormat == "json" {
+		err = writeDiffJSON(os.Stdout, report)
+	} else {
+		err = writeDiffTable(os.Stdout, report)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to format output: %w", err)
+	}
+
+	return report, nil
+}
+
+// writeDiffJSON writes the changes as a JSON document
+func writeDiffJSON(w io.Writer, report *diff.Report) error {
+	jsonOutput, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w, string(jsonOutput))
+	return err
+}
+
+// writeDiffTable writes the changes as a table
+func writeDiffTable(w io.Writer, report *diff.Report) error {
+	if !report.Changed() {
+		_, err := fmt.Fprintln(w, "No changes")
+		return err
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"Host", "Change", "Old", "New"})
+
+	for _, change := range report.Changes {
+		t.AppendRow(table.Row{change.Host, change.Kind, change.Old, change.New})
+	}
+
+	t.Style().Format.Header = text.FormatDefault
+	t.Render()
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/diff"
+	"github.com/guessi/ssl-certs-checker/pkg/output"
+)
+
+func TestApp_Diff(t *testing.T) {
+	dir := t.TempDir()
+	formatter := output.New()
+
+	write := func(name string, result *cert.Result) string {
+		path := filepath.Join(dir, name)
+		if err := formatter.WriteFile(path, result, "json"); err != nil {
+			t.Fatalf("WriteFile() unexpected error: %v", err)
+		}
+		return path
+	}
+
+	before := write("before.json", &cert.Result{
+		Certificates: []cert.CertificateInfo{{Host: "example.com:443", SerialNumber: "01", Fingerprint: "AA"}},
+		Errors:       []cert.ErrorInfo{{Host: "127.0.0.1:1", Error: "connection refused"}},
+	})
+	after := write("after.json", &cert.Result{
+		Certificates: []cert.CertificateInfo{{Host: "example.com:443", SerialNumber: "02", Fingerprint: "BB"}},
+		Errors:       []cert.ErrorInfo{{Host: "127.0.0.1:1", Error: "connection refused"}},
+	})
+	failing := write("failing.json", &cert.Result{
+		Errors: []cert.ErrorInfo{{Host: "127.0.0.1:1", Error: "connection refused"}},
+	})
+
+	tests := []struct {
+		name     string
+		cfg      config.AppConfig
+		wantCode int
+	}{
+		{
+			name:     "changed",
+			cfg:      config.AppConfig{DiffBaseline: before, DiffCurrent: after},
+			wantCode: DiffExitChanged,
+		},
+		{
+			name: "unchanged",
+			cfg:  config.AppConfig{DiffBaseline: before, DiffCurrent: before},
+		},
+		{
+			name: "changes filtered out",
+			cfg:  config.AppConfig{DiffBaseline: before, DiffCurrent: after, Filter: `host == "127.0.0.1:1"`},
+		},
+		{
+			name: "live run",
+			cfg:  config.AppConfig{DiffBaseline: failing, Domains: "127.0.0.1:1", Timeout: 5, OutputFormat: "json"},
+		},
+		{
+			name:     "live run with added host",
+			cfg:      config.AppConfig{DiffBaseline: failing, Domains: "127.0.0.1:1,127.0.0.1:2", Timeout: 5},
+			wantCode: DiffExitChanged,
+		},
+		{
+			name:     "missing baseline",
+			cfg:      config.AppConfig{DiffBaseline: filepath.Join(dir, "missing.json"), DiffCurrent: after},
+			wantCode: DiffExitTrouble,
+		},
+		{
+			name:     "live run without hosts",
+			cfg:      config.AppConfig{DiffBaseline: before, Timeout: 5},
+			wantCode: DiffExitTrouble,
+		},
+		{
+			name:     "unsupported format",
+			cfg:      config.AppConfig{DiffBaseline: before, DiffCurrent: after, OutputFormat: "nagios"},
+			wantCode: DiffExitTrouble,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Diff(context.Background(), &tt.cfg)
+
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Errorf("Diff() unexpected error: %v", err)
+				}
+				return
+			}
+
+			var exitErr *ExitError
+			if !errors.As(err, &exitErr) || exitErr.Code != tt.wantCode {
+				t.Errorf("Diff() = %v, want exit code %d", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	report := &diff.Report{Changes: []diff.Change{
+		{Host: "example.com:443", Kind: diff.ChangeIssuer, Old: "R3", New: "E5"},
+	}}
+
+	var buf bytes.Buffer
+	if err := writeDiffTable(&buf, report); err != nil {
+		t.Fatalf("writeDiffTable() unexpected error: %v", err)
+	}
+	for _, want := range []string{"example.com:443", "issuer_changed", "R3", "E5"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeDiffTable() should contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := writeDiffTable(&buf, &diff.Report{}); err != nil {
+		t.Fatalf("writeDiffTable() unexpected error: %v", err)
+	}
+	if buf.String() != "No changes\n" {
+		t.Errorf("writeDiffTable() without changes = %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := writeDiffJSON(&buf, report); err != nil {
+		t.Fatalf("writeDiffJSON() unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"kind": "issuer_changed"`) {
+		t.Errorf("writeDiffJSON() = %s", buf.String())
+	}
+}
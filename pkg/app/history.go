@@ -0,0 +1,135 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/history"
+	"github.com/guessi/ssl-certs-checker/pkg/output"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// fingerprintWidth is the number of fingerprint characters shown in tables
+const fingerprintWidth = 16
+
+// History prints the per-host timelines recorded in the history directory
+func (a *App) History(cfg *config.AppConfig) error {
+	if err := cfg.ValidateHistory(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	since, err := historySince(cfg, time.Now())
+	if err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	store, err := newHistoryStore(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+
+	entries, err := store.Entries(since)
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+
+	timelines := history.Timelines(entries)
+	if len(cfg.HistoryHosts) > 0 {
+		timelines = slices.DeleteFunc(timelines, func(timeline history.Timeline) bool {
+			return !matchHost(cfg.HistoryHosts, timeline.Host)
+		})
+	}
+
+	if cfg.OutputFormat == "json" {
+		return writeHistoryJSON(os.Stdout, timelines)
+	}
+
+	location, err := cfg.GetLocation()
+	if err != nil {
+		return err
+	}
+
+	formatter := output.NewWithOptions(output.Options{
+		TimeFormat: cfg.TimeFormat,
+		Location:   location,
+	})
+
+	return writeHistoryTable(os.Stdout, timelines, formatter)
+}
+
+// matchHost reports whether a host:port is one of the given hosts, which
+// may omit the default port
+func matchHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		if h == host || fmt.Sprintf("%s:%d", h, cert.DefaultPort) == host {
+			return true
+		}
+	}
+	return false
+}
+
+// writeHistoryJSON writes the timelines as a JSON document
+func writeHistoryJSON(w io.Writer, timelines []history.Timeline) error {
+	jsonOutput, err := json.MarshalIndent(timelines, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w, string(jsonOutput))
+	return err
+}
+
+// writeHistoryTable writes one table per host listing its entries and events
+func writeHistoryTable(w io.Writer, timelines []history.Timeline, formatter *output.Formatter) error {
+	if len(timelines) == 0 {
+		_, err := fmt.Fprintln(w, "No history recorded")
+		return err
+	}
+
+	for _, timeline := range timelines {
+		events := make(map[int64][]string)
+		for _, event := range timeline.Events {
+			events[event.At.UnixNano()] = append(events[event.At.UnixNano()], fmt.Sprintf("%s: %s", event.Kind, event.Description))
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(w)
+		t.SetTitle(timeline.Host)
+		t.AppendHeader(table.Row{"Checked At", "Status", "Days Left", "Serial", "Fingerprint", "Issuer", "Events"})
+
+		for _, entry := range timeline.Entries {
+			daysLeft := ""
+			if entry.DaysLeft != nil {
+				daysLeft = fmt.Sprint(*entry.DaysLeft)
+			}
+
+			fingerprint := entry.Fingerprint
+			if len(fingerprint) > fingerprintWidth {
+				fingerprint = fingerprint[:fingerprintWidth] + "..."
+			}
+
+			t.AppendRow(table.Row{
+				formatter.FormatTimestamp(entry.CheckedAt),
+				entry.Status,
+				daysLeft,
+				entry.SerialNumber,
+				fingerprint,
+				entry.Issuer,
+				strings.Join(events[entry.CheckedAt.UnixNano()], "\n"),
+			})
+		}
+
+		t.Style().Format.Header = text.FormatDefault
+		t.Render()
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/history"
+	"github.com/guessi/ssl-certs-checker/pkg/output"
+)
+
+func TestApp_Run_RecordsHistory(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg := &config.AppConfig{
+		Domains:              "127.0.0.1:1,127.0.0.1:2",
+		Timeout:              5,
+		OutputFormat:         "json",
+		Filter:               `host == "127.0.0.1:1"`,
+		HistoryDir:           dir,
+		HistoryRetentionDays: 30,
+	}
+
+	if err := New().Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+
+	store, err := history.New(dir, 0)
+	if err != nil {
+		t.Fatalf("history.New() unexpected error: %v", err)
+	}
+
+	entries, err := store.Entries(time.Time{})
+	if err != nil {
+		t.Fatalf("Entries() unexpected error: %v", err)
+	}
+
+	// Filtered hosts are recorded as well
+	if len(entries) != 2 || entries[0].Status != cert.StatusError {
+		t.Errorf("Run() recorded %+v, want two failed checks", entries)
+	}
+}
+
+func TestApp_History_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.AppConfig
+	}{
+		{name: "missing directory", cfg: config.AppConfig{}},
+		{name: "unsupported format", cfg: config.AppConfig{HistoryDir: t.TempDir(), OutputFormat: "junit"}},
+		{name: "invalid since", cfg: config.AppConfig{HistoryDir: t.TempDir(), HistorySince: "last week"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := New().History(&tt.cfg); err == nil {
+				t.Error("History() expected error but got none")
+			}
+		})
+	}
+}
+
+func TestWriteHistory(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysLeft := 40
+
+	timelines := history.Timelines([]history.Entry{
+		{CheckedAt: start, Host: "example.com:443", Status: cert.StatusOK, SerialNumber: "0A", Fingerprint: strings.Repeat("A", 64), Issuer: "R3", NotAfter: start.AddDate(0, 0, 40), DaysLeft: &daysLeft},
+		{CheckedAt: start.AddDate(0, 0, 1), Host: "example.com:443", Status: cert.StatusError, Error: "connection refused"},
+	})
+
+	var buf bytes.Buffer
+	if err := writeHistoryTable(&buf, timelines, output.New()); err != nil {
+		t.Fatalf("writeHistoryTable() unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"example.com:443", "2024-06-01 00:00:00 UTC", "AAAAAAAAAAAAAAAA...", "first_seen", "failing: connection refused"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeHistoryTable() should contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := writeHistoryJSON(&buf, timelines); err != nil {
+		t.Fatalf("writeHistoryJSON() unexpected error: %v", err)
+	}
+
+	var decoded []history.Timeline
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("writeHistoryJSON() produced invalid JSON: %v", err)
+	}
+	if len(decoded) != 1 || len(decoded[0].Entries) != 2 || len(decoded[0].Events) != 2 {
+		t.Errorf("writeHistoryJSON() = %+v", decoded)
+	}
+}
+
+func TestMatchHost(t *testing.T) {
+	hosts := []string{"example.com", "api.example.com:8443"}
+
+	for host, want := range map[string]bool{
+		"example.com:443":      true,
+		"api.example.com:8443": true,
+		"api.example.com:443":  false,
+		"example.org:443":      false,
+	} {
+		if got := matchHost(hosts, host); got != want {
+			t.Errorf("matchHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
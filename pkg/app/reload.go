@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/notify"
+	"github.com/guessi/ssl-certs-checker/pkg/server"
+)
+
+// reload loads the config file again and prepares a session for it
+func (a *App) reload(cfg *config.AppConfig, current *session) (*session, error) {
+	hostConfig, err := cfg.GetHostConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return a.newSession(cfg, hostConfig, current)
+}
+
+// reloadServer reconfigures the exporter whenever the config file changes,
+// until the context is cancelled. A config file failing to load keeps the
+// current one, and so does a config file whose content did not change.
+func reloadServer(ctx context.Context, cfg *config.AppConfig, current *config.Config, currentAlerts *notify.Config, exporter *server.Server, alerter *atomic.Pointer[notify.Alerter]) {
+	reloads := watchConfig(ctx, cfg.HostFiles())
+	thresholds := cert.Thresholds{WarningDays: cfg.WarningDays, CriticalDays: cfg.CriticalDays}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reloads:
+		}
+
+		hostConfig, err := cfg.GetHostConfig()
+		if err != nil {
+			log.Printf("failed to reload configuration, keeping version %s: %v", current.Version, err)
+			continue
+		}
+		if hostConfig.Version == current.Version {
+			continue
+		}
+
+		alerts, err := loadAlerts(hostConfig)
+		if err != nil {
+			log.Printf("failed to reload configuration, keeping version %s: failed to set up alerts: %v", current.Version, err)
+			continue
+		}
+		warnEmailIgnored(alerts)
+
+		// Alerts kept in memory are not sent again when they did not change
+		next := alerter.Load()
+		if alerts == nil {
+			next = nil
+		} else if next == nil || !reflect.DeepEqual(currentAlerts, alerts) {
+			if next, err = notify.New(*alerts, thresholds); err != nil {
+				log.Printf("failed to reload configuration, keeping version %s: failed to set up alerts: %v", current.Version, err)
+				continue
+			}
+		}
+
+		checker := cert.New(time.Duration(cfg.Timeout)*time.Second, cfg.Insecure)
+		checker.SetTags(hostConfig.Tags)
+		checker.SetSources(hostConfig.HostSources())
+		checker.SetLabels(hostConfig.Labels)
+
+		alerter.Store(next)
+		exporter.Reconfigure(checker, hostConfig.Hosts, hostConfig.Version)
+		log.Printf("configuration reloaded from %s (version %s)", strings.Join(cfg.HostFiles(), ", "), hostConfig.Version)
+
+		current, currentAlerts = hostConfig, alerts
+	}
+}
+
+// warnEmailIgnored logs that the email reports of the alerts are ignored by
+// the long-running commands, which would mail every recipient on each check
+func warnEmailIgnored(alerts *notify.Config) {
+	if alerts != nil && len(alerts.Email) > 0 {
+		log.Printf("email reports are only sent by single runs, ignoring the email entries of the alerts")
+	}
+}
+
+// watchConfig signals SIGHUP and modifications of the config files, including
+// files added, removed or included, it never signals without config files
+func watchConfig(ctx context.Context, patterns []string) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+	if len(patterns) == 0 {
+		return changes
+	}
+
+	signalChange := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(hangups)
+
+		ticker := time.NewTicker(configPollInterval)
+		defer ticker.Stop()
+
+		last := statFiles(patterns)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangups:
+				signalChange()
+			case <-ticker.C:
+				if current := statFiles(patterns); !slices.Equal(current, last) {
+					last = current
+					signalChange()
+				}
+			}
+		}
+	}()
+
+	return changes
+}
+
+// statFiles returns the stamps of the config files matching the patterns
+// and of the files they include
+func statFiles(patterns []string) []fileStamp {
+	var stamps []fileStamp
+	for _, path := range config.ConfigFiles(patterns) {
+		stamps = append(stamps, statFile(path))
+	}
+	return stamps
+}
+
+// statFile returns the modification time and size of a file, the zero
+// stamp when it cannot be read
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{path: path}
+	}
+	return fileStamp{path: path, modTime: info.ModTime().UnixNano(), size: info.Size()}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/notify"
+	"github.com/guessi/ssl-certs-checker/pkg/server"
+)
+
+func TestReloadServer(t *testing.T) {
+	interval := configPollInterval
+	configPollInterval = 10 * time.Millisecond
+	defer func() { configPollInterval = interval }()
+
+	configPath := filepath.Join(t.TempDir(), "hosts.yaml")
+	if err := os.WriteFile(configPath, []byte("hosts:\n  - 127.0.0.1:1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg := &config.AppConfig{ConfigFiles: []string{configPath}, Timeout: 1}
+	hostConfig, err := cfg.GetHostConfig()
+	if err != nil {
+		t.Fatalf("GetHostConfig() unexpected error: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	exporter := server.New(cert.New(time.Second, false), hostConfig.Hosts, time.Hour)
+	exporter.SetConfigVersion(hostConfig.Version)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go exporter.Serve(ctx, listener)
+
+	var alerter atomic.Pointer[notify.Alerter]
+	go reloadServer(ctx, cfg, hostConfig, nil, exporter, &alerter)
+
+	// waitForResult waits for a result checked with another config version
+	// than the given one
+	waitForResult := func(version string) *cert.Result {
+		t.Helper()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if result := exporter.Result(); result != nil && result.ConfigVersion != version {
+				return result
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("no result with another config version than %q", version)
+		return nil
+	}
+
+	if result := waitForResult(""); result.ConfigVersion != hostConfig.Version {
+		t.Fatalf("first result config version = %q, want %q", result.ConfigVersion, hostConfig.Version)
+	}
+
+	// An invalid config file keeps the current one
+	if err := os.WriteFile(configPath, []byte("hosts: [\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	// A valid one replaces it and is checked right away
+	content := "hosts:\n  - 127.0.0.1:1\n  - 127.0.0.1:2\nalerts:\n  webhooks:\n    - type: slack\n      url: https://hooks.example.com/services/T000\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	result := waitForResult(hostConfig.Version)
+	if len(result.Errors) != 2 {
+		t.Errorf("reloaded result errors = %v, want errors for both hosts", result.Errors)
+	}
+	if alerter.Load() == nil {
+		t.Error("reloadServer() should set up the alerts of the new config file")
+	}
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/notify"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// Validate loads the host configuration without connecting to the hosts and
+// prints the effective host list along with every problem found, failing
+// when there is any
+func (a *App) Validate(cfg *config.AppConfig) error {
+	if err := a.validate(cfg, cfg.Validate); err != nil {
+		return err
+	}
+
+	report := cfg.Lint()
+	lintAlerts(report)
+
+	var err error
+	if cfg.OutputFormat == "json" {
+		err = writeLintJSON(os.Stdout, report)
+	} else {
+		err = writeLintTable(os.Stdout, report)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to format output: %w", err)
+	}
+
+	if len(report.Problems) > 0 {
+		return &ExitError{Code: 1, Err: fmt.Errorf("%d problem(s) found", len(report.Problems))}
+	}
+	return nil
+}
+
+// lintAlerts adds the problems of the alerts section to the report, the
+// config package keeps the section as parsed
+func lintAlerts(report *config.LintReport) {
+	if report.Config == nil || !report.Config.HasAlerts() {
+		return
+	}
+
+	report.Problems = append(report.Problems, config.UnknownKeys(&report.Config.Alerts, &notify.Config{}, report.Config.AlertsFile)...)
+	if _, err := loadAlerts(report.Config); err != nil {
+		report.Problems = append(report.Problems, err)
+	}
+}
+
+// writeLintJSON writes the effective host list and the problems as a JSON
+// document
+func writeLintJSON(w io.Writer, report *config.LintReport) error {
+	document := lintDocument{
+		Hosts:    make([]lintHost, 0, len(report.Hosts)),
+		Problems: make([]string, 0, len(report.Problems)),
+	}
+	for _, host := range report.Hosts {
+		document.Hosts = append(document.Hosts, lintHost{
+			Host:   host.Host,
+			Source: host.Source.String(),
+			Tags:   host.Tags,
+			Labels: host.Labels,
+		})
+	}
+	for _, problem := range report.Problems {
+		document.Problems = append(document.Problems, problem.Error())
+	}
+
+	jsonOutput, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w, string(jsonOutput))
+	return err
+}
+
+// writeLintTable writes the effective host list as a table followed by the
+// problems
+func writeLintTable(w io.Writer, report *config.LintReport) error {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"Host", "Source", "Tags", "Labels"})
+
+	for _, host := range report.Hosts {
+		labels := make([]string, 0, len(host.Labels))
+		for _, name := range slices.Sorted(maps.Keys(host.Labels)) {
+			labels = append(labels, name+"="+host.Labels[name])
+		}
+
+		t.AppendRow(table.Row{
+			host.Host,
+			host.Source,
+			strings.Join(host.Tags, ", "),
+			strings.Join(labels, "\n"),
+		})
+	}
+
+	summary := fmt.Sprintf("%d host(s), %d problem(s)", len(report.Hosts), len(report.Problems))
+	t.AppendFooter(table.Row{summary, summary, summary, summary}, table.RowConfig{AutoMerge: true, AutoMergeAlign: text.AlignLeft})
+	t.Style().Format.Header = text.FormatDefault
+	t.Style().Format.Footer = text.FormatDefault
+	t.Render()
+
+	for _, problem := range report.Problems {
+		if _, err := fmt.Fprintf(w, "- %v\n", problem); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+)
+
+func TestApp_Validate(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(valid, []byte("hosts:\n  - example.com\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if err := os.WriteFile(invalid, []byte("hosts:\n  - example.com\n  - example.com:443\nhostz: []\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	alerts := filepath.Join(dir, "alerts.yaml")
+	if err := os.WriteFile(alerts, []byte("hosts:\n  - example.com\nalerts:\n  webhooks:\n    - type: slack\n      urll: https://example.com\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		cfg      config.AppConfig
+		wantCode int
+	}{
+		{name: "valid config", cfg: config.AppConfig{ConfigFiles: []string{valid}, Timeout: 5}},
+		{name: "problems", cfg: config.AppConfig{ConfigFiles: []string{invalid}, Timeout: 5}, wantCode: 1},
+		{name: "invalid alerts", cfg: config.AppConfig{ConfigFiles: []string{alerts}, Timeout: 5}, wantCode: 1},
+		{name: "private address", cfg: config.AppConfig{Domains: "192.168.0.1", Timeout: 5, DisallowPrivate: true}, wantCode: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(&tt.cfg)
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+
+			var exitErr *ExitError
+			if !errors.As(err, &exitErr) || exitErr.Code != tt.wantCode {
+				t.Errorf("Validate() error = %v, want exit code %d", err, tt.wantCode)
+			}
+		})
+	}
+
+	if err := New().Validate(&config.AppConfig{Timeout: 5}); err == nil {
+		t.Error("Validate() expected error without hosts but got none")
+	}
+}
+
+func TestLintAlerts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.yaml")
+	content := "hosts:\n  - example.com\nalerts:\n  webhooks:\n    - type: slack\n      url: https://hooks.slack.com/services/T000/B000/XXX\n      urll: https://example.com\n    - type: irc\n      url: https://example.com\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	report := (&config.AppConfig{ConfigFiles: []string{path}}).Lint()
+	lintAlerts(report)
+
+	wantProblems := []string{
+		`unknown key "urll" at ` + path + ":7",
+		"invalid alerts configuration in " + path,
+	}
+	if len(report.Problems) != len(wantProblems) {
+		t.Fatalf("lintAlerts() problems = %v, want %d problems", report.Problems, len(wantProblems))
+	}
+	for i, want := range wantProblems {
+		if !strings.Contains(report.Problems[i].Error(), want) {
+			t.Errorf("lintAlerts() problem %d = %v, want it to contain %q", i, report.Problems[i], want)
+		}
+	}
+}
+
+func TestWriteLint(t *testing.T) {
+	report := &config.LintReport{
+		Hosts: []config.LintHost{
+			{Host: "example.com:443", Source: config.Source{File: "hosts.yaml", Line: 2}, Tags: []string{"web"}, Labels: map[string]string{"team": "web", "env": "production"}},
+			{Host: "api.example.com:8443", Source: config.Source{File: "hosts.yaml", Line: 3}},
+		},
+		Problems: []error{errors.New(`unknown key "hostz" at hosts.yaml:4`)},
+	}
+
+	var buf bytes.Buffer
+	if err := writeLintTable(&buf, report); err != nil {
+		t.Fatalf("writeLintTable() unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"example.com:443", "hosts.yaml:2", "env=production", "2 host(s), 1 problem(s)", `- unknown key "hostz" at hosts.yaml:4`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeLintTable() should contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := writeLintJSON(&buf, report); err != nil {
+		t.Fatalf("writeLintJSON() unexpected error: %v", err)
+	}
+
+	var decoded lintDocument
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("writeLintJSON() produced invalid JSON: %v", err)
+	}
+	if len(decoded.Hosts) != 2 || decoded.Hosts[0].Source != "hosts.yaml:2" || decoded.Hosts[0].Labels["team"] != "web" || len(decoded.Problems) != 1 {
+		t.Errorf("writeLintJSON() = %+v", decoded)
+	}
+}
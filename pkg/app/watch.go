@@ -0,0 +1,203 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/diff"
+	"github.com/guessi/ssl-certs-checker/pkg/output"
+)
+
+// configPollInterval is how often the config file is checked for changes
+var configPollInterval = 2 * time.Second
+
+// maxStaggerShare is the share of the time until the next check staggered
+// checks are spread across, leaving the rest to show the results
+const maxStaggerShare = 0.5
+
+// Watch checks the hosts periodically in the foreground, at a fixed interval
+// or on a cron schedule, and reprints the table with the changes since the
+// previous check highlighted. The config file is reloaded on SIGHUP and when
+// it is modified, a config file failing to load keeps the previous one.
+func (a *App) Watch(ctx context.Context, cfg *config.AppConfig) error {
+	if err := a.validate(cfg, cfg.ValidateWatch); err != nil {
+		return err
+	}
+
+	sched, err := newSchedule(cfg)
+	if err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	location, err := cfg.GetLocation()
+	if err != nil {
+		return err
+	}
+
+	hostConfig, err := cfg.GetHostConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get hosts: %w", err)
+	}
+
+	s, err := a.newSession(cfg, hostConfig, nil)
+	if err != nil {
+		return err
+	}
+	warnEmailIgnored(s.alerts)
+
+	reloads := watchConfig(ctx, cfg.HostFiles())
+
+	var previous *cert.Result
+	for {
+		next := time.Now().Add(cfg.Watch)
+		if sched != nil {
+			if next = sched.Next(time.Now().In(location)); next.IsZero() {
+				return fmt.Errorf("schedule %q never fires", cfg.Schedule)
+			}
+		}
+
+		result, err := a.watchOnce(ctx, cfg, s, previous, next)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			log.Printf("%v", err)
+		}
+		if result != nil {
+			previous = result
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		for waiting := true; waiting; {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil
+			case <-timer.C:
+				waiting = false
+			case <-reloads:
+				reloaded, err := a.reload(cfg, s)
+				if err != nil {
+					log.Printf("failed to reload configuration, keeping version %s: %v", s.hostConfig.Version, err)
+					continue
+				}
+				log.Printf("configuration reloaded from %s (version %s)", strings.Join(cfg.HostFiles(), ", "), reloaded.hostConfig.Version)
+
+				warnEmailIgnored(reloaded.alerts)
+
+				// The new hosts are checked right away
+				s = reloaded
+				timer.Stop()
+				waiting = false
+			}
+		}
+	}
+}
+
+// watchOnce checks the hosts and prints the table along with the changes
+// since the previous check. It returns the filtered results shown, even when
+// writing the outputs or sending the alerts failed. The first check is never
+// staggered, so the results show up right away.
+func (a *App) watchOnce(ctx context.Context, cfg *config.AppConfig, s *session, previous *cert.Result, next time.Time) (*cert.Result, error) {
+	var checked *cert.Result
+	var err error
+	if cfg.Stagger && previous != nil {
+		window := time.Duration(float64(time.Until(next)) * maxStaggerShare)
+		checked, err = a.checkStaggered(ctx, s.hostConfig.Hosts, window)
+	} else {
+		checked, err = a.checker.CheckCertificates(ctx, s.hostConfig.Hosts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check certificates: %w", err)
+	}
+	checked.ConfigVersion = s.hostConfig.Version
+
+	if s.tracker != nil {
+		s.tracker.ObserveResult(checked)
+	}
+
+	result := s.filter.Apply(checked)
+
+	var changes *diff.Report
+	options := s.options
+	if previous != nil {
+		changes = diff.Compare(previous, result)
+		changes.Changes = append(changes.Changes, diff.CompareStatus(previous, result, s.thresholds)...)
+
+		options.Highlight = make(map[string]bool)
+		for _, change := range changes.Changes {
+			options.Highlight[change.Host] = true
+		}
+	}
+
+	out := a.stdout()
+	options.Out = out
+	a.formatter = output.NewWithOptions(options)
+
+	if output.IsTerminal(out) {
+		// Clear the screen and move the cursor home
+		fmt.Fprint(out, "\033[H\033[2J")
+	}
+	fmt.Fprintf(out, "Checked %d host(s) at %s, next check at %s\n\n",
+		len(s.hostConfig.Hosts), a.formatter.FormatTimestamp(checked.CheckedAt), a.formatter.FormatTimestamp(next))
+
+	if err := a.formatter.Format(result, output.DefaultFormat); err != nil {
+		return nil, fmt.Errorf("failed to format output: %w", err)
+	}
+
+	if changes != nil && changes.Changed() {
+		fmt.Fprintf(out, "\nChanges since the previous check:\n")
+		if err := writeDiffTable(out, changes); err != nil {
+			return nil, fmt.Errorf("failed to format output: %w", err)
+		}
+	}
+
+	return result, a.finish(ctx, cfg, s, checked, result)
+}
+
+// checkStaggered spreads the checks of the hosts evenly across the window
+// instead of checking them all at once
+func (a *App) checkStaggered(ctx context.Context, hosts []string, window time.Duration) (*cert.Result, error) {
+	result := &cert.Result{CheckedAt: time.Now()}
+	if len(hosts) == 0 {
+		return result, nil
+	}
+
+	step := window / time.Duration(len(hosts))
+	for i, host := range hosts {
+		if i > 0 && step > 0 {
+			timer := time.NewTimer(step)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		hostResult, err := a.checker.CheckCertificates(ctx, []string{host})
+		if err != nil {
+			return nil, err
+		}
+		result.Certificates = append(result.Certificates, hostResult.Certificates...)
+		result.Errors = append(result.Errors, hostResult.Errors...)
+	}
+
+	result.Duration = time.Since(result.CheckedAt)
+	return result, nil
+}
+
+// stdout returns the writer of the watch mode output
+func (a *App) stdout() io.Writer {
+	if a.out != nil {
+		return a.out
+	}
+	return os.Stdout
+}
@@ -0,0 +1,226 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/config"
+	"github.com/guessi/ssl-certs-checker/pkg/internal/tlstest"
+)
+
+// syncBuffer is a buffer safe for concurrent use
+type syncBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.String()
+}
+
+// waitFor waits until the buffer contains all the given strings
+func waitFor(t *testing.T, buf *syncBuffer, want ...string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		found := true
+		for _, s := range want {
+			if !strings.Contains(buf.String(), s) {
+				found = false
+			}
+		}
+		if found {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("output should contain %q:\n%s", want, buf.String())
+}
+
+func TestApp_Watch(t *testing.T) {
+	interval := configPollInterval
+	configPollInterval = 10 * time.Millisecond
+	defer func() { configPollInterval = interval }()
+
+	host := tlstest.StartServer(t, time.Now().Add(-time.Hour), time.Now().AddDate(0, 1, 0))
+	configPath := filepath.Join(t.TempDir(), "hosts.yaml")
+	if err := os.WriteFile(configPath, []byte("hosts:\n  - "+host+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg := &config.AppConfig{
+		ConfigFiles:  []string{configPath},
+		Timeout:      5,
+		Insecure:     true,
+		WarningDays:  30,
+		CriticalDays: 7,
+		Watch:        time.Hour,
+	}
+
+	var out syncBuffer
+	application := New()
+	application.out = &out
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- application.Watch(ctx, cfg) }()
+
+	waitFor(t, &out, "Checked 1 host(s)", host, "Config version: ")
+
+	// Editing the config file checks the new hosts right away
+	if err := os.WriteFile(configPath, []byte("hosts:\n  - "+host+"\n  - 127.0.0.1:1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	waitFor(t, &out, "Checked 2 host(s)", "Changes since the previous check:", "127.0.0.1:1", "added")
+
+	// An invalid config file keeps the previous one
+	if err := os.WriteFile(configPath, []byte("hosts: [\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Watch() unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch() did not return after cancellation")
+	}
+
+	if strings.Count(out.String(), "Checked ") != 2 {
+		t.Errorf("Watch() should have checked twice:\n%s", out.String())
+	}
+}
+
+func TestApp_Watch_IgnoresEmail(t *testing.T) {
+	tempDir := t.TempDir()
+	outbox := filepath.Join(tempDir, "outbox")
+	configPath := filepath.Join(tempDir, "hosts.yaml")
+	content := "hosts:\n  - 127.0.0.1:1\nalerts:\n  email:\n    - from: certs@example.com\n      recipients: [ops@example.com]\n      dry_run: " + outbox + "\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg := &config.AppConfig{
+		ConfigFiles:  []string{configPath},
+		Timeout:      5,
+		WarningDays:  30,
+		CriticalDays: 7,
+		Watch:        time.Hour,
+	}
+
+	var out, logs syncBuffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	application := New()
+	application.out = &out
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- application.Watch(ctx, cfg) }()
+
+	waitFor(t, &out, "Checked 1 host(s)", "1 error(s)")
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Watch() unexpected error: %v", err)
+	}
+
+	// Checking on every interval would mail the recipients each time
+	if _, err := os.Stat(outbox); !os.IsNotExist(err) {
+		t.Errorf("Watch() should not send email reports, stat error = %v", err)
+	}
+	if !strings.Contains(logs.String(), "ignoring the email entries") {
+		t.Errorf("Watch() should warn about the ignored email reports:\n%s", logs.String())
+	}
+}
+
+func TestApp_Watch_InvalidConfig(t *testing.T) {
+	cfg := &config.AppConfig{Domains: "example.com", Timeout: 5, Watch: time.Hour, OutputFormat: "json"}
+
+	err := New().Watch(context.Background(), cfg)
+	if err == nil || !strings.Contains(err.Error(), "watch mode only supports the table output format") {
+		t.Errorf("Watch() error = %v, want output format error", err)
+	}
+}
+
+func TestApp_WatchOnce_Stagger(t *testing.T) {
+	cfg := &config.AppConfig{Domains: "127.0.0.1:1,127.0.0.1:2", Timeout: 5, Watch: time.Hour, Stagger: true}
+	hostConfig, err := cfg.GetHostConfig()
+	if err != nil {
+		t.Fatalf("GetHostConfig() unexpected error: %v", err)
+	}
+
+	application := New()
+	application.out = &bytes.Buffer{}
+	s, err := application.newSession(cfg, hostConfig, nil)
+	if err != nil {
+		t.Fatalf("newSession() unexpected error: %v", err)
+	}
+
+	// The first check is not staggered across the hour until the next one
+	start := time.Now()
+	previous, err := application.watchOnce(context.Background(), cfg, s, nil, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("watchOnce() unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("watchOnce() took %v, want the first check not staggered", elapsed)
+	}
+
+	// The next checks only take part of the time until the following one
+	start = time.Now()
+	if _, err := application.watchOnce(context.Background(), cfg, s, previous, start.Add(400*time.Millisecond)); err != nil {
+		t.Fatalf("watchOnce() unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed >= 400*time.Millisecond {
+		t.Errorf("watchOnce() took %v, want the checks spread across half of the time until the next check", elapsed)
+	}
+}
+
+func TestApp_CheckStaggered(t *testing.T) {
+	host := tlstest.StartServer(t, time.Now().Add(-time.Hour), time.Now().AddDate(0, 1, 0))
+
+	application := New()
+	application.checker = cert.New(5*time.Second, true)
+
+	start := time.Now()
+	result, err := application.checkStaggered(context.Background(), []string{host, "127.0.0.1:1"}, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("checkStaggered() unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("checkStaggered() took %v, want the second host checked halfway through the window", elapsed)
+	}
+	if len(result.Certificates) != 1 || len(result.Errors) != 1 {
+		t.Errorf("checkStaggered() = %+v, want one certificate and one error", result)
+	}
+
+	// Cancellation interrupts the wait between hosts
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := application.checkStaggered(ctx, []string{host, host}, time.Hour); err == nil {
+		t.Error("checkStaggered() should fail once the context is cancelled")
+	}
+}
@@ -2,8 +2,11 @@ package cert
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
 	"strconv"
@@ -26,8 +29,39 @@ func New(timeout time.Duration, insecure bool) *Checker {
 	}
 }
 
+// OnStart registers a function called with the host:port of every host when
+// its check starts, after waiting for a free connection slot
+func (c *Checker) OnStart(fn func(host string)) {
+	c.onStart = fn
+}
+
+// SetTags attaches tags to the results of hosts, keyed by the host as given
+// to CheckCertificates
+func (c *Checker) SetTags(tags map[string][]string) {
+	c.tags = tags
+}
+
+// SetSources attaches the location of their definition, such as a config
+// file and line, to the results of hosts, keyed by the host as given to
+// CheckCertificates
+func (c *Checker) SetSources(sources map[string]string) {
+	c.sources = sources
+}
+
+// SetLabels attaches named values such as their team to the results of
+// hosts, keyed by the host as given to CheckCertificates
+func (c *Checker) SetLabels(labels map[string]map[string]string) {
+	c.labels = labels
+}
+
 // CheckCertificates checks SSL certificates for multiple hosts concurrently
 func (c *Checker) CheckCertificates(ctx context.Context, hosts []string) (*Result, error) {
+	return c.CheckCertificatesFunc(ctx, hosts, nil)
+}
+
+// CheckCertificatesFunc is like CheckCertificates but also calls fn with the
+// outcome of each host as soon as its check completes, calls are serialized
+func (c *Checker) CheckCertificatesFunc(ctx context.Context, hosts []string, fn func(Outcome)) (*Result, error) {
 	if len(hosts) == 0 {
 		return nil, fmt.Errorf("no hosts provided")
 	}
@@ -35,58 +69,111 @@ func (c *Checker) CheckCertificates(ctx context.Context, hosts []string) (*Resul
 	result := &Result{
 		Certificates: make([]CertificateInfo, 0),
 		Errors:       make([]ErrorInfo, 0),
+		CheckedAt:    time.Now(),
 	}
 
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
 
+	record := func(outcome Outcome) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if outcome.Error != nil {
+			result.Errors = append(result.Errors, *outcome.Error)
+		} else if outcome.Certificate != nil {
+			result.Certificates = append(result.Certificates, *outcome.Certificate)
+		}
+
+		if fn != nil {
+			fn(outcome)
+		}
+	}
+
 	// Limit concurrent connections to be respectful to target servers
 	semaphore := make(chan struct{}, MaxConcurrency)
 
 	for _, hostStr := range hosts {
 		select {
 		case <-ctx.Done():
+			// Wait for running checks so fn is never called after returning
+			wg.Wait()
 			return nil, ctx.Err()
 		default:
 		}
 
+		tags := c.tags[hostStr]
+		source := c.sources[hostStr]
+		labels := c.labels[hostStr]
+
 		hostname, port, err := parseHost(hostStr)
 		if err != nil {
-			mutex.Lock()
-			result.Errors = append(result.Errors, ErrorInfo{
-				Host:  hostStr,
-				Error: fmt.Sprintf("invalid host format: %v", err),
-			})
-			mutex.Unlock()
+			record(Outcome{Error: &ErrorInfo{
+				Host:   hostStr,
+				Error:  fmt.Sprintf("invalid host format: %v", err),
+				Kind:   ErrorKindInvalidHost,
+				Tags:   tags,
+				Labels: labels,
+				Source: source,
+			}})
 			continue
 		}
 
 		wg.Add(1)
-		go func(host string, p int) {
+		go func(host string, p int, tags []string, labels map[string]string, source string) {
 			defer wg.Done()
 
 			semaphore <- struct{}{}        // Acquire
 			defer func() { <-semaphore }() // Release
 
+			if c.onStart != nil {
+				c.onStart(fmt.Sprintf("%s:%d", host, p))
+			}
+
+			start := time.Now()
 			certInfo, err := c.getCertInfoByHost(ctx, host, p)
+			elapsed := time.Since(start)
 
-			mutex.Lock()
 			if err != nil {
-				result.Errors = append(result.Errors, ErrorInfo{
-					Host:  fmt.Sprintf("%s:%d", host, p),
-					Error: err.Error(),
-				})
+				record(Outcome{Error: &ErrorInfo{
+					Host:     fmt.Sprintf("%s:%d", host, p),
+					Error:    err.Error(),
+					Kind:     classifyError(err),
+					Tags:     tags,
+					Labels:   labels,
+					Source:   source,
+					Duration: elapsed,
+				}})
 			} else if certInfo != nil {
-				result.Certificates = append(result.Certificates, *certInfo)
+				certInfo.Tags = tags
+				certInfo.Labels = labels
+				certInfo.Source = source
+				certInfo.Duration = elapsed
+				record(Outcome{Certificate: certInfo})
 			}
-			mutex.Unlock()
-		}(hostname, port)
+		}(hostname, port, tags, labels, source)
 	}
 
 	wg.Wait()
+	result.Duration = time.Since(result.CheckedAt)
 	return result, nil
 }
 
+// classifyError tells certificate verification failures apart from plain connection failures
+func classifyError(err error) ErrorKind {
+	var verifyErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+
+	if errors.As(err, &verifyErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return ErrorKindVerification
+	}
+
+	return ErrorKindConnection
+}
+
 // getCertInfoByHost get SSL certificate info by host
 func (c *Checker) getCertInfoByHost(ctx context.Context, hostname string, port int) (*CertificateInfo, error) {
 	if hostname == "" {
@@ -106,18 +193,35 @@ func (c *Checker) getCertInfoByHost(ctx context.Context, hostname string, port i
 
 		return &CertificateInfo{
 			Host:               fmt.Sprintf("%s:%d", hostname, port),
+			ServerName:         hostname,
 			CommonName:         cert.Subject.CommonName,
 			DNSNames:           cert.DNSNames,
 			NotBefore:          cert.NotBefore,
 			NotAfter:           cert.NotAfter,
 			PublicKeyAlgorithm: cert.PublicKeyAlgorithm.String(),
 			Issuer:             cert.Issuer.CommonName,
+			SerialNumber:       formatSerialNumber(cert),
+			Fingerprint:        formatFingerprint(cert),
 		}, nil
 	}
 
 	return nil, fmt.Errorf("no valid leaf certificate found")
 }
 
+// formatSerialNumber formats the certificate serial number as uppercase hex
+func formatSerialNumber(cert *x509.Certificate) string {
+	if cert.SerialNumber == nil {
+		return ""
+	}
+	return strings.ToUpper(cert.SerialNumber.Text(16))
+}
+
+// formatFingerprint returns the SHA-256 fingerprint of the certificate as uppercase hex
+func formatFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
 // getPeerCertificates retrieves raw certificates from the server
 func (c *Checker) getPeerCertificates(ctx context.Context, hostname string, port int) ([]*x509.Certificate, error) {
 	// Create a context with timeout for the entire operation
@@ -182,6 +286,16 @@ func formatAddress(hostname string, port int) string {
 	return fmt.Sprintf("%s:%d", hostname, port)
 }
 
+// NormalizeHost returns a host as lowercase hostname:port, with the default
+// port when it has none, so that two spellings of a host compare equal
+func NormalizeHost(host string) (string, error) {
+	hostname, port, err := parseHost(host)
+	if err != nil {
+		return "", err
+	}
+	return formatAddress(strings.ToLower(hostname), port), nil
+}
+
 // parseHost parses a host string into hostname and port
 func parseHost(hostStr string) (hostname string, port int, err error) {
 	hostStr = strings.TrimSpace(hostStr)
@@ -2,8 +2,12 @@ package cert
 
 import (
 	"context"
+	"reflect"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/internal/tlstest"
 )
 
 func TestParseHost(t *testing.T) {
@@ -271,6 +275,178 @@ func TestCheckCertificates_RealHost(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckCertificates_LocalTLSServer(t *testing.T) {
+	host := tlstest.StartServer(t, time.Now().Add(-time.Hour), time.Now().AddDate(0, 1, 0))
+
+	// The test server uses a self-signed certificate, so verification fails
+	result, err := New(5*time.Second, false).CheckCertificates(context.Background(), []string{host})
+	if err != nil {
+		t.Fatalf("CheckCertificates() unexpected error: %v", err)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Kind != ErrorKindVerification {
+		t.Errorf("CheckCertificates() errors = %+v, want one verification error", result.Errors)
+	}
+
+	// Insecure mode accepts the certificate and records timings
+	result, err = New(5*time.Second, true).CheckCertificates(context.Background(), []string{host})
+	if err != nil {
+		t.Fatalf("CheckCertificates() unexpected error: %v", err)
+	}
+	if len(result.Certificates) != 1 {
+		t.Fatalf("CheckCertificates() certificates = %+v, errors = %+v", result.Certificates, result.Errors)
+	}
+	if result.Certificates[0].SerialNumber != "1092" {
+		t.Errorf("CheckCertificates() serial number = %s, want 1092", result.Certificates[0].SerialNumber)
+	}
+	if result.Certificates[0].ServerName != "127.0.0.1" {
+		t.Errorf("CheckCertificates() server name = %s, want 127.0.0.1", result.Certificates[0].ServerName)
+	}
+	if len(result.Certificates[0].Fingerprint) != 64 {
+		t.Errorf("CheckCertificates() fingerprint = %s, want a SHA-256 hex digest", result.Certificates[0].Fingerprint)
+	}
+	if result.Certificates[0].Duration <= 0 {
+		t.Error("CheckCertificates() should record the check duration")
+	}
+	if result.CheckedAt.IsZero() || result.Duration <= 0 {
+		t.Error("CheckCertificates() should record the run timing")
+	}
+}
+
+func TestCheckCertificatesFunc(t *testing.T) {
+	host := tlstest.StartServer(t, time.Now().Add(-time.Hour), time.Now().AddDate(0, 1, 0))
+	hosts := []string{host, "invalid::host", "127.0.0.1:1"}
+
+	var outcomes []Outcome
+	result, err := New(5*time.Second, true).CheckCertificatesFunc(context.Background(), hosts, func(outcome Outcome) {
+		outcomes = append(outcomes, outcome)
+	})
+	if err != nil {
+		t.Fatalf("CheckCertificatesFunc() unexpected error: %v", err)
+	}
+
+	if len(outcomes) != len(hosts) {
+		t.Fatalf("CheckCertificatesFunc() called back %d time(s), want %d", len(outcomes), len(hosts))
+	}
+
+	var certificates, errors int
+	for _, outcome := range outcomes {
+		switch {
+		case outcome.Certificate != nil && outcome.Error == nil:
+			certificates++
+		case outcome.Error != nil && outcome.Certificate == nil:
+			errors++
+		default:
+			t.Errorf("CheckCertificatesFunc() outcome should hold either a certificate or an error: %+v", outcome)
+		}
+	}
+
+	if certificates != len(result.Certificates) || errors != len(result.Errors) {
+		t.Errorf("CheckCertificatesFunc() outcomes (%d/%d) do not match result (%d/%d)",
+			certificates, errors, len(result.Certificates), len(result.Errors))
+	}
+}
+
+func TestChecker_OnStart(t *testing.T) {
+	host := tlstest.StartServer(t, time.Now().Add(-time.Hour), time.Now().AddDate(0, 1, 0))
+	hosts := []string{host, "example.com:99999", "127.0.0.1:1"}
+
+	var started sync.Map
+	checker := New(5*time.Second, true)
+	checker.OnStart(func(host string) {
+		started.Store(host, true)
+	})
+
+	if _, err := checker.CheckCertificates(context.Background(), hosts); err != nil {
+		t.Fatalf("CheckCertificates() unexpected error: %v", err)
+	}
+
+	// Invalid hosts are never started
+	for _, want := range []string{host, "127.0.0.1:1"} {
+		if _, ok := started.Load(want); !ok {
+			t.Errorf("OnStart() was not called for %s", want)
+		}
+	}
+	if _, ok := started.Load("example.com:99999"); ok {
+		t.Error("OnStart() should not be called for invalid hosts")
+	}
+}
+
+func TestCheckCertificates_Tags(t *testing.T) {
+	host := tlstest.StartServer(t, time.Now().Add(-time.Hour), time.Now().AddDate(0, 1, 0))
+
+	checker := New(5*time.Second, true)
+	checker.SetTags(map[string][]string{
+		host:                {"production"},
+		"example.com:99999": {"staging", "web"},
+	})
+
+	result, err := checker.CheckCertificates(context.Background(), []string{host, "example.com:99999", "127.0.0.1:1"})
+	if err != nil {
+		t.Fatalf("CheckCertificates() unexpected error: %v", err)
+	}
+
+	if len(result.Certificates) != 1 || !reflect.DeepEqual(result.Certificates[0].Tags, []string{"production"}) {
+		t.Errorf("CheckCertificates() certificates = %+v, want tag production", result.Certificates)
+	}
+
+	tags := make(map[string][]string)
+	for _, e := range result.Errors {
+		tags[e.Host] = e.Tags
+	}
+	if !reflect.DeepEqual(tags["example.com:99999"], []string{"staging", "web"}) || tags["127.0.0.1:1"] != nil {
+		t.Errorf("CheckCertificates() error tags = %v", tags)
+	}
+}
+
+func TestCheckCertificates_Sources(t *testing.T) {
+	host := tlstest.StartServer(t, time.Now().Add(-time.Hour), time.Now().AddDate(0, 1, 0))
+
+	checker := New(5*time.Second, true)
+	checker.SetSources(map[string]string{
+		host:          "hosts.yaml:2",
+		"127.0.0.1:1": "conf.d/team.yaml:4",
+	})
+
+	result, err := checker.CheckCertificates(context.Background(), []string{host, "127.0.0.1:1"})
+	if err != nil {
+		t.Fatalf("CheckCertificates() unexpected error: %v", err)
+	}
+
+	if len(result.Certificates) != 1 || result.Certificates[0].Source != "hosts.yaml:2" {
+		t.Errorf("CheckCertificates() certificates = %+v, want source hosts.yaml:2", result.Certificates)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Source != "conf.d/team.yaml:4" {
+		t.Errorf("CheckCertificates() errors = %+v, want source conf.d/team.yaml:4", result.Errors)
+	}
+}
+
+func TestCheckCertificates_Labels(t *testing.T) {
+	host := tlstest.StartServer(t, time.Now().Add(-time.Hour), time.Now().AddDate(0, 1, 0))
+
+	checker := New(5*time.Second, true)
+	checker.SetLabels(map[string]map[string]string{
+		host:          {"team": "web"},
+		"127.0.0.1:1": {"team": "db"},
+	})
+
+	result, err := checker.CheckCertificates(context.Background(), []string{host, "127.0.0.1:1", "127.0.0.1:2"})
+	if err != nil {
+		t.Fatalf("CheckCertificates() unexpected error: %v", err)
+	}
+
+	if len(result.Certificates) != 1 || result.Certificates[0].Labels["team"] != "web" {
+		t.Errorf("CheckCertificates() certificates = %+v, want team web", result.Certificates)
+	}
+	labels := make(map[string]map[string]string)
+	for _, errInfo := range result.Errors {
+		labels[errInfo.Host] = errInfo.Labels
+	}
+	if labels["127.0.0.1:1"]["team"] != "db" || labels["127.0.0.1:2"] != nil {
+		t.Errorf("CheckCertificates() error labels = %v", labels)
+	}
+}
+
 func TestFormatAddress(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -319,3 +495,37 @@ func TestFormatAddress(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		want    string
+		wantErr bool
+	}{
+		{host: "example.com", want: "example.com:443"},
+		{host: "Example.COM:443", want: "example.com:443"},
+		{host: " example.com:8443 ", want: "example.com:8443"},
+		{host: "192.168.1.1", want: "192.168.1.1:443"},
+		{host: "[2001:DB8::1]:8443", want: "[2001:db8::1]:8443"},
+		{host: "::1", want: "[::1]:443"},
+		{host: "example.com:99999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got, err := NormalizeHost(tt.host)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("NormalizeHost() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeHost() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeHost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,26 +5,64 @@ import (
 )
 
 type CertificateInfo struct {
-	Host               string    `json:"host"`
-	CommonName         string    `json:"common_name"`
-	DNSNames           []string  `json:"dns_names"`
-	NotBefore          time.Time `json:"not_before"`
-	NotAfter           time.Time `json:"not_after"`
-	PublicKeyAlgorithm string    `json:"public_key_algorithm"`
-	Issuer             string    `json:"issuer"`
+	Host               string            `json:"host"`
+	ServerName         string            `json:"server_name,omitempty"`
+	CommonName         string            `json:"common_name"`
+	DNSNames           []string          `json:"dns_names"`
+	NotBefore          time.Time         `json:"not_before"`
+	NotAfter           time.Time         `json:"not_after"`
+	PublicKeyAlgorithm string            `json:"public_key_algorithm"`
+	Issuer             string            `json:"issuer"`
+	SerialNumber       string            `json:"serial_number"`
+	Fingerprint        string            `json:"fingerprint_sha256"`
+	Tags               []string          `json:"tags,omitempty"`
+	Labels             map[string]string `json:"labels,omitempty"`
+	Source             string            `json:"source,omitempty"`
+	Duration           time.Duration     `json:"duration"`
 }
 
+type ErrorKind string
+
+const (
+	ErrorKindInvalidHost  ErrorKind = "invalid_host"
+	ErrorKindConnection   ErrorKind = "connection"
+	ErrorKindVerification ErrorKind = "verification"
+)
+
 type ErrorInfo struct {
-	Host  string `json:"host"`
-	Error string `json:"error"`
+	Host     string            `json:"host"`
+	Error    string            `json:"error"`
+	Kind     ErrorKind         `json:"kind,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Source   string            `json:"source,omitempty"`
+	Duration time.Duration     `json:"duration"`
 }
 
 type Result struct {
 	Certificates []CertificateInfo `json:"certificates"`
 	Errors       []ErrorInfo       `json:"errors,omitempty"`
+	CheckedAt    time.Time         `json:"checked_at"`
+	Duration     time.Duration     `json:"duration"`
+	Rotations    []Rotation        `json:"rotations,omitempty"`
+	Summary      *Summary          `json:"summary,omitempty"`
+	// ConfigVersion identifies the config file the hosts were loaded from
+	ConfigVersion string `json:"config_version,omitempty"`
+}
+
+// Outcome holds the result of checking a single host, either a certificate
+// or an error, along with the rotation of the certificate when detected
+type Outcome struct {
+	Certificate *CertificateInfo
+	Error       *ErrorInfo
+	Rotation    *Rotation
 }
 
 type Checker struct {
 	timeout  time.Duration
 	insecure bool
+	onStart  func(host string)
+	tags     map[string][]string
+	sources  map[string]string
+	labels   map[string]map[string]string
 }
@@ -0,0 +1,21 @@
+package cert
+
+// Version returns the version of the certificate, used to detect rotations
+func (ci CertificateInfo) Version() CertificateVersion {
+	return CertificateVersion{
+		SerialNumber:       ci.SerialNumber,
+		Fingerprint:        ci.Fingerprint,
+		NotAfter:           ci.NotAfter,
+		Issuer:             ci.Issuer,
+		PublicKeyAlgorithm: ci.PublicKeyAlgorithm,
+	}
+}
+
+// SameCertificate reports whether both versions are the same certificate,
+// comparing fingerprints and falling back to serial numbers
+func (v CertificateVersion) SameCertificate(other CertificateVersion) bool {
+	if v.Fingerprint != "" && other.Fingerprint != "" {
+		return v.Fingerprint == other.Fingerprint
+	}
+	return v.SerialNumber == other.SerialNumber
+}
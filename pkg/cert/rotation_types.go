@@ -0,0 +1,21 @@
+package cert
+
+import "time"
+
+// CertificateVersion identifies one of the certificates served by a host
+type CertificateVersion struct {
+	SerialNumber       string    `json:"serial_number"`
+	Fingerprint        string    `json:"fingerprint_sha256,omitempty"`
+	NotAfter           time.Time `json:"not_after"`
+	Issuer             string    `json:"issuer"`
+	PublicKeyAlgorithm string    `json:"public_key_algorithm"`
+}
+
+// Rotation records that a host started serving a different certificate
+type Rotation struct {
+	Host       string             `json:"host"`
+	ServerName string             `json:"server_name,omitempty"`
+	DetectedAt time.Time          `json:"detected_at"`
+	Old        CertificateVersion `json:"old"`
+	New        CertificateVersion `json:"new"`
+}
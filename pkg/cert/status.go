@@ -0,0 +1,44 @@
+package cert
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	DefaultWarningDays  = 30
+	DefaultCriticalDays = 7
+)
+
+// DefaultThresholds returns the default expiry thresholds
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		WarningDays:  DefaultWarningDays,
+		CriticalDays: DefaultCriticalDays,
+	}
+}
+
+// DaysLeft returns the number of whole days until the certificate expires,
+// negative once it has expired
+func (ci CertificateInfo) DaysLeft(now time.Time) int {
+	return int(math.Floor(ci.NotAfter.Sub(now).Hours() / 24))
+}
+
+// Evaluate returns the status of a certificate along with a human readable reason
+func (t Thresholds) Evaluate(ci CertificateInfo, now time.Time) (Status, string) {
+	daysLeft := ci.DaysLeft(now)
+
+	switch {
+	case now.Before(ci.NotBefore):
+		return StatusCritical, fmt.Sprintf("certificate is not valid before %s", ci.NotBefore.UTC().Format(time.RFC3339))
+	case !now.Before(ci.NotAfter):
+		return StatusCritical, fmt.Sprintf("certificate expired %d day(s) ago", -daysLeft)
+	case daysLeft < t.CriticalDays:
+		return StatusCritical, fmt.Sprintf("certificate expires in %d day(s)", daysLeft)
+	case daysLeft < t.WarningDays:
+		return StatusWarning, fmt.Sprintf("certificate expires in %d day(s)", daysLeft)
+	default:
+		return StatusOK, fmt.Sprintf("certificate expires in %d day(s)", daysLeft)
+	}
+}
@@ -0,0 +1,63 @@
+package cert
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCertificateInfo_DaysLeft(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		notAfter time.Time
+		want     int
+	}{
+		{name: "far future", notAfter: now.AddDate(0, 0, 90), want: 90},
+		{name: "partial day", notAfter: now.Add(36 * time.Hour), want: 1},
+		{name: "less than a day", notAfter: now.Add(time.Hour), want: 0},
+		{name: "just expired", notAfter: now.Add(-time.Hour), want: -1},
+		{name: "expired days ago", notAfter: now.AddDate(0, 0, -3), want: -3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CertificateInfo{NotAfter: tt.notAfter}.DaysLeft(now)
+			if got != tt.want {
+				t.Errorf("DaysLeft() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThresholds_Evaluate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	thresholds := DefaultThresholds()
+
+	tests := []struct {
+		name      string
+		notBefore time.Time
+		notAfter  time.Time
+		want      Status
+	}{
+		{name: "healthy", notBefore: now.AddDate(0, -1, 0), notAfter: now.AddDate(0, 0, 60), want: StatusOK},
+		{name: "on warning boundary", notBefore: now.AddDate(0, -1, 0), notAfter: now.AddDate(0, 0, 30), want: StatusOK},
+		{name: "warning", notBefore: now.AddDate(0, -1, 0), notAfter: now.AddDate(0, 0, 29), want: StatusWarning},
+		{name: "critical", notBefore: now.AddDate(0, -1, 0), notAfter: now.AddDate(0, 0, 6), want: StatusCritical},
+		{name: "expired", notBefore: now.AddDate(-1, 0, 0), notAfter: now.AddDate(0, 0, -1), want: StatusCritical},
+		{name: "not yet valid", notBefore: now.AddDate(0, 0, 1), notAfter: now.AddDate(1, 0, 0), want: StatusCritical},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certInfo := CertificateInfo{NotBefore: tt.notBefore, NotAfter: tt.notAfter}
+			got, reason := thresholds.Evaluate(certInfo, now)
+			if got != tt.want {
+				t.Errorf("Evaluate() = %s (%s), want %s", got, reason, tt.want)
+			}
+			if reason == "" {
+				t.Error("Evaluate() returned an empty reason")
+			}
+		})
+	}
+}
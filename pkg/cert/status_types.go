@@ -0,0 +1,15 @@
+package cert
+
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusWarning  Status = "warning"
+	StatusCritical Status = "critical"
+	StatusError    Status = "error"
+)
+
+type Thresholds struct {
+	WarningDays  int
+	CriticalDays int
+}
@@ -0,0 +1,59 @@
+package cert
+
+import (
+	"slices"
+	"time"
+)
+
+// Summarize computes the statistics of a result, certificates are counted by
+// their status and failed checks as errors
+func (t Thresholds) Summarize(result *Result, now time.Time) Summary {
+	summary := Summary{
+		Total:         len(result.Certificates) + len(result.Errors),
+		Errors:        len(result.Errors),
+		Issuers:       make(map[string]int),
+		KeyAlgorithms: make(map[string]int),
+	}
+
+	serials := make(map[string][]string)
+
+	for _, certInfo := range result.Certificates {
+		status, _ := t.Evaluate(certInfo, now)
+		switch status {
+		case StatusOK:
+			summary.OK++
+		case StatusWarning:
+			summary.Warning++
+		case StatusCritical:
+			summary.Critical++
+		}
+
+		summary.Issuers[certInfo.Issuer]++
+		summary.KeyAlgorithms[certInfo.PublicKeyAlgorithm]++
+
+		if summary.SoonestExpiry == nil || certInfo.NotAfter.Before(summary.SoonestExpiry.NotAfter) {
+			summary.SoonestExpiry = &Expiry{
+				Host:     certInfo.Host,
+				NotAfter: certInfo.NotAfter,
+				DaysLeft: certInfo.DaysLeft(now),
+			}
+		}
+
+		if certInfo.SerialNumber != "" && !slices.Contains(serials[certInfo.SerialNumber], certInfo.Host) {
+			serials[certInfo.SerialNumber] = append(serials[certInfo.SerialNumber], certInfo.Host)
+		}
+	}
+
+	for serial, hosts := range serials {
+		if len(hosts) < 2 {
+			continue
+		}
+		if summary.SharedSerials == nil {
+			summary.SharedSerials = make(map[string][]string)
+		}
+		slices.Sort(hosts)
+		summary.SharedSerials[serial] = hosts
+	}
+
+	return summary
+}
@@ -0,0 +1,52 @@
+package cert
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestThresholds_Summarize(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	result := &Result{
+		Certificates: []CertificateInfo{
+			{Host: "a.example.com:443", Issuer: "R3", PublicKeyAlgorithm: "RSA", SerialNumber: "0A", NotBefore: now.AddDate(0, -1, 0), NotAfter: now.AddDate(0, 0, 90)},
+			{Host: "b.example.com:443", Issuer: "R3", PublicKeyAlgorithm: "RSA", SerialNumber: "0A", NotBefore: now.AddDate(0, -1, 0), NotAfter: now.AddDate(0, 0, 90)},
+			{Host: "c.example.com:443", Issuer: "E1", PublicKeyAlgorithm: "ECDSA", SerialNumber: "0B", NotBefore: now.AddDate(0, -1, 0), NotAfter: now.AddDate(0, 0, 12)},
+			{Host: "d.example.com:443", Issuer: "R3", PublicKeyAlgorithm: "RSA", SerialNumber: "0C", NotBefore: now.AddDate(0, -3, 0), NotAfter: now.AddDate(0, 0, -3)},
+		},
+		Errors: []ErrorInfo{
+			{Host: "e.example.com:443", Error: "connection refused"},
+		},
+	}
+
+	summary := DefaultThresholds().Summarize(result, now)
+
+	want := Summary{
+		Total:    5,
+		OK:       2,
+		Warning:  1,
+		Critical: 1,
+		Errors:   1,
+		SoonestExpiry: &Expiry{
+			Host:     "d.example.com:443",
+			NotAfter: now.AddDate(0, 0, -3),
+			DaysLeft: -3,
+		},
+		Issuers:       map[string]int{"R3": 3, "E1": 1},
+		KeyAlgorithms: map[string]int{"RSA": 3, "ECDSA": 1},
+		SharedSerials: map[string][]string{"0A": {"a.example.com:443", "b.example.com:443"}},
+	}
+
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("Summarize() = %+v, want %+v", summary, want)
+	}
+}
+
+func TestThresholds_Summarize_Empty(t *testing.T) {
+	summary := DefaultThresholds().Summarize(&Result{}, time.Now())
+
+	if summary.Total != 0 || summary.SoonestExpiry != nil || summary.SharedSerials != nil {
+		t.Errorf("Summarize() of an empty result = %+v", summary)
+	}
+}
@@ -0,0 +1,23 @@
+package cert
+
+import "time"
+
+// Summary holds statistics computed over the results of a run
+type Summary struct {
+	Total         int                 `json:"total"`
+	OK            int                 `json:"ok"`
+	Warning       int                 `json:"warning"`
+	Critical      int                 `json:"critical"`
+	Errors        int                 `json:"errors"`
+	SoonestExpiry *Expiry             `json:"soonest_expiry,omitempty"`
+	Issuers       map[string]int      `json:"issuers"`
+	KeyAlgorithms map[string]int      `json:"key_algorithms"`
+	SharedSerials map[string][]string `json:"shared_serials,omitempty"`
+}
+
+// Expiry identifies the certificate expiring first
+type Expiry struct {
+	Host     string    `json:"host"`
+	NotAfter time.Time `json:"not_after"`
+	DaysLeft int       `json:"days_left"`
+}
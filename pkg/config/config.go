@@ -1,49 +1,24 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
-
-	"go.yaml.in/yaml/v3"
+	"syscall"
+	"time"
 )
 
-// LoadConfig loads configuration from a YAML file
+// LoadConfig loads configuration from a YAML, JSON, TOML or plain text file
+// and the files it includes
 func LoadConfig(configPath string) (*Config, error) {
 	if configPath == "" {
 		return nil, fmt.Errorf("config file path cannot be empty")
 	}
 
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("config file does not exist: %s", configPath)
-	}
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("cannot read config file: %w", err)
-	}
-
-	if len(data) == 0 {
-		return nil, fmt.Errorf("config file is empty")
-	}
-
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("invalid YAML format: %w", err)
-	}
-
-	if len(config.Hosts) == 0 {
-		return nil, fmt.Errorf("no hosts found in config file")
-	}
-
-	for i, host := range config.Hosts {
-		if err := validateHost(host); err != nil {
-			return nil, fmt.Errorf("invalid host at index %d: %w", i, err)
-		}
-	}
-
-	return &config, nil
+	return LoadConfigs([]string{configPath})
 }
 
 // ParseDomainsFromString parses a comma-separated string of domains
@@ -143,33 +118,241 @@ func validateHost(host string) error {
 
 // Validate validates the application configuration
 func (c *AppConfig) Validate() error {
-	if c.ConfigFile == "" && c.Domains == "" {
-		return fmt.Errorf("either --config or --domains must be specified")
+	if len(c.HostFiles()) == 0 && c.Domains == "" {
+		return fmt.Errorf("either --config, --hosts-from, --hosts-csv or --domains must be specified")
+	}
+
+	if len(c.HostFiles()) > 0 && c.Domains != "" {
+		return fmt.Errorf("--config, --hosts-from and --hosts-csv cannot be used with --domains")
+	}
+
+	stdinReads := 0
+	for _, file := range c.HostFiles() {
+		if file == stdinPath {
+			stdinReads++
+		}
+	}
+	if stdinReads > 1 {
+		return fmt.Errorf("stdin can only be read once, by --config, --hosts-from or --hosts-csv")
 	}
 
-	if c.ConfigFile != "" && c.Domains != "" {
-		return fmt.Errorf("--config and --domains cannot be used together")
+	if c.HostsCSV == "" && (c.HostColumn != "" || c.PortColumn != "" || len(c.TagColumns) > 0) {
+		return fmt.Errorf("--host-column, --port-column and --tag-columns require --hosts-csv")
+	}
+
+	if c.OutputFormat != "" && c.CheckFormat != nil {
+		if err := c.CheckFormat(c.OutputFormat); err != nil {
+			return err
+		}
+	}
+
+	if c.GroupBy != "" && c.OutputFormat != "" && c.OutputFormat != "table" {
+		return fmt.Errorf("--group-by is only supported by the table output format")
 	}
 
 	if c.Timeout <= 0 {
 		return fmt.Errorf("timeout must be positive")
 	}
 
-	if c.OutputFormat != "" && c.OutputFormat != "table" && c.OutputFormat != "json" && c.OutputFormat != "yaml" {
-		return fmt.Errorf("invalid output format: %s (supported: table, json, yaml)", c.OutputFormat)
+	if c.WarningDays < 0 || c.CriticalDays < 0 {
+		return fmt.Errorf("warning and critical days must not be negative")
+	}
+
+	if c.CriticalDays > c.WarningDays {
+		return fmt.Errorf("critical days (%d) must not exceed warning days (%d)", c.CriticalDays, c.WarningDays)
+	}
+
+	if _, err := c.GetLocation(); err != nil {
+		return err
+	}
+
+	if c.HistoryRetentionDays < 0 {
+		return fmt.Errorf("history retention must not be negative")
+	}
+
+	return nil
+}
+
+// ValidateServe validates the configuration of the long-running exporter mode
+func (c *AppConfig) ValidateServe() error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	if c.ListenAddress == "" {
+		return fmt.Errorf("listen address cannot be empty")
+	}
+
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+
+	return nil
+}
+
+// Watching reports whether the hosts are checked periodically in the
+// foreground
+func (c *AppConfig) Watching() bool {
+	return c.Watch != 0 || c.Schedule != ""
+}
+
+// ValidateWatch validates the configuration of the watch mode
+func (c *AppConfig) ValidateWatch() error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	if c.Watch != 0 && c.Schedule != "" {
+		return fmt.Errorf("--watch and --schedule cannot be used together")
+	}
+
+	if c.Watch < 0 {
+		return fmt.Errorf("watch interval must be positive")
+	}
+
+	if c.OutputFormat != "" && c.OutputFormat != "table" {
+		return fmt.Errorf("watch mode only supports the table output format")
 	}
 
 	return nil
 }
 
+// ValidateHistory validates the configuration of the history command
+func (c *AppConfig) ValidateHistory() error {
+	if c.HistoryDir == "" {
+		return fmt.Errorf("--history-dir must be specified")
+	}
+
+	if c.HistoryRetentionDays < 0 {
+		return fmt.Errorf("history retention must not be negative")
+	}
+
+	if err := validateReportFormat("history", c.OutputFormat); err != nil {
+		return err
+	}
+
+	_, err := c.GetLocation()
+	return err
+}
+
+// ValidateDiff validates the configuration of the diff command, comparing
+// with a live run when no current result file is given
+func (c *AppConfig) ValidateDiff() error {
+	if c.DiffBaseline == "" {
+		return fmt.Errorf("a baseline result file must be specified")
+	}
+
+	if err := validateReportFormat("diff", c.OutputFormat); err != nil {
+		return err
+	}
+
+	if c.DiffCurrent == "" {
+		return c.Validate()
+	}
+
+	return nil
+}
+
+// validateReportFormat checks that a command printing a report supports the
+// output format
+func validateReportFormat(command, format string) error {
+	if format != "" && format != "table" && format != "json" {
+		return fmt.Errorf("%s supports the table and json output formats only", command)
+	}
+	return nil
+}
+
+// GetTemplate returns the output template, read from a file when the
+// configured value names an existing file and used inline otherwise
+func (c *AppConfig) GetTemplate() (string, error) {
+	if c.Template == "" {
+		return "", nil
+	}
+
+	// A template that is not a file is given inline, inline templates too
+	// long to be a file name included
+	info, err := os.Stat(c.Template)
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ENAMETOOLONG) {
+		return c.Template, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot read template file: %w", err)
+	}
+	if info.IsDir() {
+		return c.Template, nil
+	}
+
+	data, err := os.ReadFile(c.Template)
+	if err != nil {
+		return "", fmt.Errorf("cannot read template file: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// GetLocation returns the time zone timestamps are displayed in, UTC by
+// default
+func (c *AppConfig) GetLocation() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %s", c.Timezone)
+	}
+
+	return location, nil
+}
+
+// HostFiles returns the config files, patterns and host lists the hosts are
+// loaded from, - standing for stdin
+func (c *AppConfig) HostFiles() []string {
+	files := slices.Clone(c.ConfigFiles)
+	for _, file := range []string{c.HostsFrom, c.HostsCSV} {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
 // GetHosts returns the list of hosts based on the configuration
 func (c *AppConfig) GetHosts() ([]string, error) {
-	if c.ConfigFile != "" {
-		config, err := LoadConfig(c.ConfigFile)
+	config, err := c.GetHostConfig()
+	if err != nil {
+		return nil, err
+	}
+	return config.Hosts, nil
+}
+
+// GetHostConfig returns the hosts together with their tags, hosts given
+// with --domains have no tags
+func (c *AppConfig) GetHostConfig() (*Config, error) {
+	if len(c.HostFiles()) > 0 {
+		l := newLoader()
+		if err := l.loadAll(c.ConfigFiles); err != nil {
+			return nil, fmt.Errorf("failed to load config file: %w", err)
+		}
+
+		if c.HostsFrom != "" {
+			if err := l.load(c.HostsFrom, FormatText); err != nil {
+				return nil, fmt.Errorf("failed to load hosts: %w", err)
+			}
+		}
+
+		if c.HostsCSV != "" {
+			options := CSVOptions{HostColumn: c.HostColumn, PortColumn: c.PortColumn, TagColumns: c.TagColumns}
+			if err := l.loadCSV(c.HostsCSV, options); err != nil {
+				return nil, fmt.Errorf("failed to load hosts: %w", err)
+			}
+		}
+
+		config, err := l.finish()
 		if err != nil {
 			return nil, fmt.Errorf("failed to load config file: %w", err)
 		}
-		return config.Hosts, nil
+		return config, nil
 	}
 
 	if c.Domains != "" {
@@ -177,7 +360,7 @@ func (c *AppConfig) GetHosts() ([]string, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse domains: %w", err)
 		}
-		return hosts, nil
+		return &Config{Hosts: hosts}, nil
 	}
 
 	return nil, fmt.Errorf("no hosts configuration provided")
@@ -1,9 +1,16 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/notify"
 )
 
 func TestParseDomainsFromString(t *testing.T) {
@@ -257,6 +264,168 @@ func TestLoadConfig(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_Tags(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		want    map[string][]string
+		wantErr bool
+	}{
+		{
+			name:    "tags for hosts",
+			content: "hosts:\n  - example.com\n  - api.example.com:8443\ntags:\n  api.example.com:8443: [production, api]\n",
+			want:    map[string][]string{"api.example.com:8443": {"production", "api"}},
+		},
+		{
+			name:    "tags for unknown host",
+			content: "hosts:\n  - example.com\ntags:\n  example.org: [production]\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty tag",
+			content: "hosts:\n  - example.com\ntags:\n  example.com: [\"\"]\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, "hosts.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			config, err := LoadConfig(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("LoadConfig() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(config.Tags, tt.want) {
+				t.Errorf("LoadConfig() tags = %v, want %v", config.Tags, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Version(t *testing.T) {
+	tempDir := t.TempDir()
+
+	load := func(content string) string {
+		t.Helper()
+
+		path := filepath.Join(tempDir, "hosts.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+
+		config, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("LoadConfig() unexpected error: %v", err)
+		}
+		return config.Version
+	}
+
+	first := load("hosts:\n  - example.com\n")
+	if len(first) != configVersionLength {
+		t.Errorf("LoadConfig() version = %q, want %d hex digits", first, configVersionLength)
+	}
+	if again := load("hosts:\n  - example.com\n"); again != first {
+		t.Errorf("LoadConfig() version = %q for the same content, want %q", again, first)
+	}
+	if changed := load("hosts:\n  - example.org\n"); changed == first {
+		t.Errorf("LoadConfig() version should change with the content, got %q", changed)
+	}
+}
+
+func TestLoadConfig_Alerts(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name: "webhooks",
+			content: `hosts:
+  - example.com
+alerts:
+  state_file: /tmp/alerts.json
+  webhooks:
+    - type: slack
+      url: https://hooks.slack.com/services/T000/B000/XXXX
+      min_status: critical
+      send_resolved: true
+    - name: receiver
+      type: generic
+      url: https://alerts.example.com/hook
+      timeout: 3s
+      tags: [production]
+      headers:
+        Authorization: Bearer token
+      body: '{"text": {{json .Text}}}'
+`,
+		},
+		{
+			name:    "invalid webhooks",
+			content: "hosts:\n  - example.com\nalerts:\n  webhooks: https://example.com\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, "hosts.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			config, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig() unexpected error: %v", err)
+			}
+
+			var alerts notify.Config
+			err = config.DecodeAlerts(&alerts)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid alerts configuration in "+path) {
+					t.Errorf("DecodeAlerts() error = %v, want the config file in it", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeAlerts() unexpected error: %v", err)
+			}
+
+			if alerts.StateFile != "/tmp/alerts.json" || len(alerts.Webhooks) != 2 {
+				t.Fatalf("DecodeAlerts() alerts = %+v", alerts)
+			}
+			if slack := alerts.Webhooks[0]; slack.MinStatus != cert.StatusCritical || !slack.SendResolved {
+				t.Errorf("DecodeAlerts() slack webhook = %+v", slack)
+			}
+			generic := alerts.Webhooks[1]
+			if generic.Timeout != 3*time.Second || generic.Headers["Authorization"] != "Bearer token" || !reflect.DeepEqual(generic.Tags, []string{"production"}) {
+				t.Errorf("DecodeAlerts() generic webhook = %+v", generic)
+			}
+		})
+	}
+}
+
+// checkFormat accepts the json and table output formats
+func checkFormat(name string) error {
+	if name != "json" && name != "table" {
+		return fmt.Errorf("invalid output format: %s", name)
+	}
+	return nil
+}
+
 func TestAppConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -266,7 +435,7 @@ func TestAppConfig_Validate(t *testing.T) {
 		{
 			name: "valid config with config file",
 			config: AppConfig{
-				ConfigFile:   "config.yaml",
+				ConfigFiles:  []string{"config.yaml"},
 				Timeout:      5,
 				OutputFormat: "table",
 			},
@@ -286,6 +455,25 @@ func TestAppConfig_Validate(t *testing.T) {
 				Timeout: 5,
 			},
 		},
+		{
+			name: "registered output format",
+			config: AppConfig{
+				Domains:      "example.com",
+				Timeout:      5,
+				OutputFormat: "json",
+				CheckFormat:  checkFormat,
+			},
+		},
+		{
+			name: "unregistered output format",
+			config: AppConfig{
+				Domains:      "example.com",
+				Timeout:      5,
+				OutputFormat: "xml",
+				CheckFormat:  checkFormat,
+			},
+			wantErr: true,
+		},
 		{
 			name: "no config or domains",
 			config: AppConfig{
@@ -296,12 +484,61 @@ func TestAppConfig_Validate(t *testing.T) {
 		{
 			name: "both config and domains",
 			config: AppConfig{
-				ConfigFile: "config.yaml",
+				ConfigFiles: []string{"config.yaml"},
+				Domains:     "example.com",
+				Timeout:     5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid config with hosts from stdin and a CSV inventory",
+			config: AppConfig{
+				ConfigFiles: []string{"config.yaml"},
+				HostsFrom:   "-",
+				HostsCSV:    "inventory.csv",
+				HostColumn:  "fqdn",
+				TagColumns:  []string{"team"},
+				GroupBy:     "team",
+				Timeout:     5,
+			},
+		},
+		{
+			name: "hosts from file and domains",
+			config: AppConfig{
+				HostsFrom: "hosts.txt",
+				Domains:   "example.com",
+				Timeout:   5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "stdin read twice",
+			config: AppConfig{
+				ConfigFiles: []string{"-"},
+				HostsCSV:    "-",
+				Timeout:     5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "CSV columns without inventory",
+			config: AppConfig{
 				Domains:    "example.com",
+				HostColumn: "fqdn",
 				Timeout:    5,
 			},
 			wantErr: true,
 		},
+		{
+			name: "group by with JSON output",
+			config: AppConfig{
+				Domains:      "example.com",
+				GroupBy:      "team",
+				OutputFormat: "json",
+				Timeout:      5,
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid timeout",
 			config: AppConfig{
@@ -311,11 +548,81 @@ func TestAppConfig_Validate(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "invalid output format",
+			name: "valid config with junit output and thresholds",
 			config: AppConfig{
 				Domains:      "example.com",
 				Timeout:      5,
-				OutputFormat: "xml",
+				OutputFormat: "junit",
+				WarningDays:  30,
+				CriticalDays: 7,
+			},
+		},
+		{
+			name: "valid config with output files",
+			config: AppConfig{
+				Domains:      "example.com",
+				Timeout:      5,
+				OutputFormat: "table",
+				OutputFiles:  []string{"report.json", "prometheus=/tmp/metrics.txt"},
+			},
+		},
+		{
+			name: "negative threshold",
+			config: AppConfig{
+				Domains:     "example.com",
+				Timeout:     5,
+				WarningDays: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "critical threshold above warning threshold",
+			config: AppConfig{
+				Domains:      "example.com",
+				Timeout:      5,
+				WarningDays:  7,
+				CriticalDays: 30,
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid timezone",
+			config: AppConfig{
+				Domains:  "example.com",
+				Timeout:  5,
+				Timezone: "Local",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown timezone",
+			config: AppConfig{
+				Domains:  "example.com",
+				Timeout:  5,
+				Timezone: "Mars/Olympus_Mons",
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid filters",
+			config: AppConfig{
+				Domains:        "example.com",
+				Timeout:        5,
+				OnlyFailing:    true,
+				ExpiringWithin: "30d",
+				IssuerMatch:    "^Let's Encrypt",
+				Tags:           []string{"production"},
+				Filter:         "days_left < 14",
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative history retention",
+			config: AppConfig{
+				Domains:              "example.com",
+				Timeout:              5,
+				HistoryDir:           "history",
+				HistoryRetentionDays: -1,
 			},
 			wantErr: true,
 		},
@@ -337,3 +644,142 @@ func TestAppConfig_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestAppConfig_ValidateServe(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  AppConfig
+		wantErr bool
+	}{
+		{
+			name: "valid serve config",
+			config: AppConfig{
+				Domains:       "example.com",
+				Timeout:       5,
+				ListenAddress: ":9219",
+				Interval:      time.Minute,
+			},
+		},
+		{
+			name: "missing listen address",
+			config: AppConfig{
+				Domains:  "example.com",
+				Timeout:  5,
+				Interval: time.Minute,
+			},
+			wantErr: true,
+		},
+		{
+			name: "non-positive interval",
+			config: AppConfig{
+				Domains:       "example.com",
+				Timeout:       5,
+				ListenAddress: ":9219",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid base config",
+			config: AppConfig{
+				Timeout:       5,
+				ListenAddress: ":9219",
+				Interval:      time.Minute,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.ValidateServe()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AppConfig.ValidateServe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppConfig_ValidateWatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  AppConfig
+		wantErr bool
+	}{
+		{
+			name:   "interval",
+			config: AppConfig{Domains: "example.com", Timeout: 5, Watch: time.Hour},
+		},
+		{
+			name:   "schedule",
+			config: AppConfig{Domains: "example.com", Timeout: 5, Schedule: "0 9 * * mon-fri", OutputFormat: "table"},
+		},
+		{
+			name:    "interval and schedule",
+			config:  AppConfig{Domains: "example.com", Timeout: 5, Watch: time.Hour, Schedule: "@daily"},
+			wantErr: true,
+		},
+		{
+			name:    "negative interval",
+			config:  AppConfig{Domains: "example.com", Timeout: 5, Watch: -time.Hour},
+			wantErr: true,
+		},
+		{
+			name:    "non-table output",
+			config:  AppConfig{Domains: "example.com", Timeout: 5, Watch: time.Hour, OutputFormat: "json"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.config.Watching() {
+				t.Fatal("AppConfig.Watching() = false, want true")
+			}
+			err := tt.config.ValidateWatch()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AppConfig.ValidateWatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppConfig_GetTemplate(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), "report.tmpl")
+	if err := os.WriteFile(templatePath, []byte("{{ len .Certificates }}"), 0644); err != nil {
+		t.Fatalf("Failed to write template file: %v", err)
+	}
+
+	longTemplate := strings.Repeat("{{ len .Certificates }} ", 20)
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{name: "no template", template: "", want: ""},
+		{name: "inline template", template: "{{ .Errors }}", want: "{{ .Errors }}"},
+		{name: "long inline template", template: longTemplate, want: longTemplate},
+		{name: "template file", template: templatePath, want: "{{ len .Certificates }}"},
+		{name: "unreadable template path", template: filepath.Join(templatePath, "report.tmpl"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &AppConfig{Template: tt.template}
+			got, err := cfg.GetTemplate()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("GetTemplate() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetTemplate() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,87 @@
 package config
 
+import (
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
 type Config struct {
-	Hosts []string `yaml:"hosts"`
+	Hosts []string            `yaml:"hosts"`
+	Tags  map[string][]string `yaml:"tags"`
+	// Alerts is the alerts section, kept as parsed for DecodeAlerts
+	Alerts  yaml.Node `yaml:"alerts"`
+	Include []string  `yaml:"include"`
+	// Labels holds named values of hosts, such as their team, shown along
+	// with their results
+	Labels map[string]map[string]string `yaml:"labels"`
+
+	// Version identifies the content of the config files, empty for hosts
+	// given on the command line
+	Version string `yaml:"-"`
+	// Sources locates the definition of every host in the config files
+	Sources map[string]Source `yaml:"-"`
+	// Files lists the config files loaded, included files included
+	Files []string `yaml:"-"`
+	// AlertsFile is the config file the alerts are defined in
+	AlertsFile string `yaml:"-"`
+}
+
+// Source locates a line of a config file
+type Source struct {
+	File string
+	Line int
 }
 
 type AppConfig struct {
-	ConfigFile   string
+	ConfigFiles  []string
+	HostsFrom    string
+	HostsCSV     string
+	HostColumn   string
+	PortColumn   string
+	TagColumns   []string
+	GroupBy      string
 	Domains      string
 	Timeout      int
 	Insecure     bool
 	OutputFormat string
+	Template     string
+	// CheckFormat checks that the output format is registered, such as
+	// output.Check, the format is not checked when nil
+	CheckFormat  func(name string) error
+	OutputFiles  []string
+	Textfile     string
+	NoProgress   bool
+	NoColor      bool
+	Compact      bool
+	TimeFormat   string
+	Timezone     string
+	WarningDays  int
+	CriticalDays int
+
+	OnlyFailing    bool
+	ExpiringWithin string
+	IssuerMatch    string
+	Tags           []string
+	Filter         string
+
+	StateFile string
+
+	HistoryDir           string
+	HistoryRetentionDays int
+	HistoryHosts         []string
+	HistorySince         string
+
+	DiffBaseline string
+	DiffCurrent  string
+
+	ListenAddress string
+	Interval      time.Duration
+
+	// DisallowPrivate makes Lint report hosts with private addresses
+	DisallowPrivate bool
+
+	Watch    time.Duration
+	Schedule string
+	Stagger  bool
 }
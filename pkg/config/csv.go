@@ -0,0 +1,120 @@
+package config
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+)
+
+// DefaultHostColumn is the CSV column holding the hosts by default
+const DefaultHostColumn = "host"
+
+// loadCSV merges the hosts of a CSV inventory with a header row, the tag
+// columns of every row becoming tags and labels of its host
+func (l *loader) loadCSV(path string, options CSVOptions) error {
+	data, err := l.read(path)
+	if err != nil {
+		return err
+	}
+	name := sourceName(path)
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("invalid CSV format in %s: %w", name, err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	column := func(title string) (int, error) {
+		if i := slices.Index(header, title); i >= 0 {
+			return i, nil
+		}
+		return -1, fmt.Errorf("column %q not found in %s", title, name)
+	}
+
+	hostColumn := options.HostColumn
+	if hostColumn == "" {
+		hostColumn = DefaultHostColumn
+	}
+	hostIndex, err := column(hostColumn)
+	if err != nil {
+		return err
+	}
+
+	portIndex := -1
+	if options.PortColumn != "" {
+		if portIndex, err = column(options.PortColumn); err != nil {
+			return err
+		}
+	}
+
+	tagIndexes := make([]int, len(options.TagColumns))
+	for i, tagColumn := range options.TagColumns {
+		if tagIndexes[i], err = column(tagColumn); err != nil {
+			return err
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			if err := l.report(fmt.Errorf("invalid CSV format in %s: %w", name, err)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		line, _ := reader.FieldPos(hostIndex)
+		source := Source{File: name, Line: line}
+
+		host := strings.TrimSpace(record[hostIndex])
+		if host == "" {
+			continue
+		}
+		if portIndex >= 0 {
+			if port := strings.TrimSpace(record[portIndex]); port != "" {
+				if _, _, err := net.SplitHostPort(host); err == nil {
+					if err := l.report(fmt.Errorf("host %s already has a port, cannot add port %s of column %q at %s", host, port, options.PortColumn, source)); err != nil {
+						return err
+					}
+					continue
+				}
+				host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+			}
+		}
+
+		if err := l.addHost(host, source); err != nil {
+			if err := l.report(err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var tags []string
+		labels := make(map[string]string)
+		for i, tagColumn := range options.TagColumns {
+			if value := strings.TrimSpace(record[tagIndexes[i]]); value != "" {
+				tags = append(tags, value)
+				labels[tagColumn] = value
+			}
+		}
+		if err := l.addTags(host, tags); err != nil {
+			return fmt.Errorf("%w at %s", err, source)
+		}
+		if err := l.addLabels(host, labels); err != nil {
+			return fmt.Errorf("%w at %s", err, source)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAppConfig_GetHostConfig_CSV(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"hosts.yaml":    "hosts:\n  - example.com\n",
+		"inventory.csv": "fqdn,port,team,env\nexample.com,,web,production\napi.example.com,8443,web,staging\n,,db,production\ndb.example.com,5432,db,\n",
+	})
+
+	appConfig := AppConfig{
+		ConfigFiles: []string{filepath.Join(dir, "hosts.yaml")},
+		HostsCSV:    filepath.Join(dir, "inventory.csv"),
+		HostColumn:  "fqdn",
+		PortColumn:  "port",
+		TagColumns:  []string{"team", "env"},
+	}
+	config, err := appConfig.GetHostConfig()
+	if err != nil {
+		t.Fatalf("GetHostConfig() unexpected error: %v", err)
+	}
+
+	wantHosts := []string{"example.com", "api.example.com:8443", "db.example.com:5432"}
+	if !reflect.DeepEqual(config.Hosts, wantHosts) {
+		t.Errorf("GetHostConfig() hosts = %v, want %v", config.Hosts, wantHosts)
+	}
+
+	wantTags := map[string][]string{
+		"example.com":          {"web", "production"},
+		"api.example.com:8443": {"web", "staging"},
+		"db.example.com:5432":  {"db"},
+	}
+	if !reflect.DeepEqual(config.Tags, wantTags) {
+		t.Errorf("GetHostConfig() tags = %v, want %v", config.Tags, wantTags)
+	}
+
+	wantLabels := map[string]map[string]string{
+		"example.com":          {"team": "web", "env": "production"},
+		"api.example.com:8443": {"team": "web", "env": "staging"},
+		"db.example.com:5432":  {"team": "db"},
+	}
+	if !reflect.DeepEqual(config.Labels, wantLabels) {
+		t.Errorf("GetHostConfig() labels = %v, want %v", config.Labels, wantLabels)
+	}
+
+	wantSources := map[string]string{
+		"example.com":          filepath.Join(dir, "hosts.yaml") + ":2",
+		"api.example.com:8443": filepath.Join(dir, "inventory.csv") + ":3",
+		"db.example.com:5432":  filepath.Join(dir, "inventory.csv") + ":5",
+	}
+	if sources := config.HostSources(); !reflect.DeepEqual(sources, wantSources) {
+		t.Errorf("GetHostConfig() sources = %v, want %v", sources, wantSources)
+	}
+}
+
+func TestAppConfig_GetHostConfig_CSVErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		inventory string
+		options   CSVOptions
+		wantErr   string
+	}{
+		{
+			name:      "default host column missing",
+			inventory: "fqdn\nexample.com\n",
+			wantErr:   `column "host" not found`,
+		},
+		{
+			name:      "tag column missing",
+			inventory: "host,team\nexample.com,web\n",
+			options:   CSVOptions{TagColumns: []string{"env"}},
+			wantErr:   `column "env" not found`,
+		},
+		{
+			name:      "invalid port points to its line",
+			inventory: "host,port\nexample.com,443\nexample.org,99999\n",
+			options:   CSVOptions{PortColumn: "port"},
+			wantErr:   "inventory.csv:3",
+		},
+		{
+			name:      "port in both columns",
+			inventory: "host,port\nexample.com,443\nexample.org:8443,443\n",
+			options:   CSVOptions{PortColumn: "port"},
+			wantErr:   `host example.org:8443 already has a port, cannot add port 443 of column "port" at`,
+		},
+		{
+			name:      "rows of different lengths",
+			inventory: "host,team\nexample.com\n",
+			wantErr:   "invalid CSV format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeConfigFiles(t, dir, map[string]string{"inventory.csv": tt.inventory})
+
+			appConfig := AppConfig{
+				HostsCSV:   filepath.Join(dir, "inventory.csv"),
+				HostColumn: tt.options.HostColumn,
+				PortColumn: tt.options.PortColumn,
+				TagColumns: tt.options.TagColumns,
+			}
+			_, err := appConfig.GetHostConfig()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GetHostConfig() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppConfig_GetHostConfig_CSVPorts(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"inventory.csv": "host,port\nexample.com,8443\n[2001:db8::1],8443\n2001:db8::2,443\n[2001:db8::3]:8443,\n",
+	})
+
+	appConfig := AppConfig{HostsCSV: filepath.Join(dir, "inventory.csv"), PortColumn: "port"}
+	config, err := appConfig.GetHostConfig()
+	if err != nil {
+		t.Fatalf("GetHostConfig() unexpected error: %v", err)
+	}
+
+	wantHosts := []string{"example.com:8443", "[2001:db8::1]:8443", "[2001:db8::2]:443", "[2001:db8::3]:8443"}
+	if !reflect.DeepEqual(config.Hosts, wantHosts) {
+		t.Errorf("GetHostConfig() hosts = %v, want %v", config.Hosts, wantHosts)
+	}
+}
+
+func TestAppConfig_GetHostConfig_Stdin(t *testing.T) {
+	tests := []struct {
+		name      string
+		appConfig AppConfig
+		input     string
+		wantHosts []string
+	}{
+		{
+			name:      "config from stdin",
+			appConfig: AppConfig{ConfigFiles: []string{"-"}},
+			input:     "hosts = [\"example.com\", \"example.org:8443\"]\n",
+			wantHosts: []string{"example.com", "example.org:8443"},
+		},
+		{
+			name:      "hosts from stdin",
+			appConfig: AppConfig{HostsFrom: "-"},
+			input:     "# from the inventory\nexample.com\nexample.org:8443\n",
+			wantHosts: []string{"example.com", "example.org:8443"},
+		},
+		{
+			name:      "CSV inventory from stdin",
+			appConfig: AppConfig{HostsCSV: "-"},
+			input:     "host\nexample.com\n",
+			wantHosts: []string{"example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := stdin
+			stdin = &stdinReader{reader: strings.NewReader(tt.input)}
+			t.Cleanup(func() { stdin = saved })
+
+			// Reloading sees the content of stdin again
+			for range 2 {
+				config, err := tt.appConfig.GetHostConfig()
+				if err != nil {
+					t.Fatalf("GetHostConfig() unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(config.Hosts, tt.wantHosts) {
+					t.Errorf("GetHostConfig() hosts = %v, want %v", config.Hosts, tt.wantHosts)
+				}
+				if source := config.Sources[tt.wantHosts[0]]; source.File != "stdin" {
+					t.Errorf("GetHostConfig() source = %v, want stdin", source)
+				}
+			}
+		})
+	}
+}
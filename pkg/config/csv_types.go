@@ -0,0 +1,12 @@
+package config
+
+// CSVOptions selects the columns of a CSV inventory hosts are read from
+type CSVOptions struct {
+	// HostColumn holds the host names, optionally with a port
+	HostColumn string
+	// PortColumn holds the ports, the host column alone when empty
+	PortColumn string
+	// TagColumns hold values that become both tags and labels named after
+	// their column
+	TagColumns []string
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	// secretFilePrefix references a secret read from a file
+	secretFilePrefix = "file:"
+	// secretEnvPrefix references a secret read from an environment variable
+	secretEnvPrefix = "env:"
+)
+
+// parseConfigFile parses the content of a config file in the given format,
+// detected when empty, and expands the environment variables referenced by
+// its values
+func parseConfigFile(path string, data []byte, format string) (*yaml.Node, error) {
+	root, err := parseDocument(path, data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := expandNode(path, root); err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// expandNode expands the environment variables referenced by the scalars of
+// a parsed config file
+func expandNode(path string, node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if !strings.Contains(node.Value, "${") {
+			return nil
+		}
+
+		expanded, err := expandVariables(node.Value)
+		if err != nil {
+			return fmt.Errorf("%s at %s", err, Source{File: path, Line: node.Line})
+		}
+		node.Value = expanded
+
+		// Plain values are resolved again, so a variable can hold a number
+		if node.Style == 0 {
+			node.Tag = ""
+		}
+		return nil
+	}
+
+	for _, child := range node.Content {
+		if err := expandNode(path, child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expandVariables replaces ${VAR} with the value of the environment variable
+// VAR, and ${VAR:-default} with default when VAR is unset or empty. $${ is
+// kept as a literal ${.
+func expandVariables(s string) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1])
+			b.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable reference %q", s[start:])
+		}
+
+		value, err := lookupVariable(s[start+2 : start+end])
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(s[:start])
+		b.WriteString(value)
+		s = s[start+end+1:]
+	}
+}
+
+// lookupVariable returns the value of a variable reference, VAR or
+// VAR:-default
+func lookupVariable(reference string) (string, error) {
+	name, fallback, hasDefault := strings.Cut(reference, ":-")
+	if !validVariableName(name) {
+		return "", fmt.Errorf("invalid variable name %q", name)
+	}
+
+	value, ok := os.LookupEnv(name)
+	if hasDefault && value == "" {
+		return fallback, nil
+	}
+	if !ok {
+		return "", fmt.Errorf("undefined variable %s", name)
+	}
+
+	return value, nil
+}
+
+// validVariableName reports whether name is a valid environment variable name
+func validVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// ResolveSecret returns the secret a value references, the content of a file
+// for file:/path and the value of an environment variable for env:NAME. Other
+// values are returned as is.
+func ResolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, secretFilePrefix):
+		path := strings.TrimPrefix(value, secretFilePrefix)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("cannot read secret file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+
+	case strings.HasPrefix(value, secretEnvPrefix):
+		name := strings.TrimPrefix(value, secretEnvPrefix)
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret environment variable %s is not set", name)
+		}
+		return secret, nil
+	}
+
+	return value, nil
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/guessi/ssl-certs-checker/pkg/notify"
+)
+
+func TestExpandVariables(t *testing.T) {
+	t.Setenv("CHECKER_DOMAIN", "example.com")
+	t.Setenv("CHECKER_EMPTY", "")
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "no reference", input: "example.com:443", want: "example.com:443"},
+		{name: "variable", input: "api.${CHECKER_DOMAIN}:8443", want: "api.example.com:8443"},
+		{name: "several variables", input: "${CHECKER_DOMAIN}/${CHECKER_DOMAIN}", want: "example.com/example.com"},
+		{name: "default unused", input: "${CHECKER_DOMAIN:-example.org}", want: "example.com"},
+		{name: "default for unset", input: "${CHECKER_UNSET:-example.org}", want: "example.org"},
+		{name: "default for empty", input: "${CHECKER_EMPTY:-example.org}", want: "example.org"},
+		{name: "empty default", input: "a${CHECKER_UNSET:-}b", want: "ab"},
+		{name: "empty variable", input: "a${CHECKER_EMPTY}b", want: "ab"},
+		{name: "escaped", input: "$${CHECKER_DOMAIN}", want: "${CHECKER_DOMAIN}"},
+		{name: "undefined", input: "${CHECKER_UNSET}", wantErr: "undefined variable CHECKER_UNSET"},
+		{name: "unterminated", input: "${CHECKER_DOMAIN", wantErr: "unterminated"},
+		{name: "invalid name", input: "${1DOMAIN}", wantErr: "invalid variable name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandVariables(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expandVariables() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandVariables() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expandVariables() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveSecret(t *testing.T) {
+	secretPath := filepath.Join(t.TempDir(), "webhook")
+	if err := os.WriteFile(secretPath, []byte("https://hooks.example.com/secret\n"), 0600); err != nil {
+		t.Fatalf("Failed to write secret file: %v", err)
+	}
+	t.Setenv("CHECKER_SECRET", "s3cret")
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain value", value: "https://hooks.example.com/plain", want: "https://hooks.example.com/plain"},
+		{name: "file", value: "file:" + secretPath, want: "https://hooks.example.com/secret"},
+		{name: "env", value: "env:CHECKER_SECRET", want: "s3cret"},
+		{name: "missing file", value: "file:" + secretPath + ".missing", wantErr: true},
+		{name: "unset env", value: "env:CHECKER_UNSET", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveSecret(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("ResolveSecret() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveSecret() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveSecret() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Expansion(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "routing-key"), []byte("R0UT1NG\n"), 0600); err != nil {
+		t.Fatalf("Failed to write secret file: %v", err)
+	}
+	t.Setenv("CHECKER_ENV", "staging")
+	t.Setenv("CHECKER_SMTP_PASSWORD", "p4ss")
+
+	content := `hosts:
+  - ${CHECKER_ENV}.example.com
+  - api.${CHECKER_ENV}.example.com:${CHECKER_API_PORT:-8443}
+tags:
+  ${CHECKER_ENV}.example.com: ["${CHECKER_ENV}"]
+alerts:
+  events:
+    - routing_key: file:` + filepath.Join(dir, "routing-key") + `
+  email:
+    - smtp:
+        host: smtp.example.com
+        port: ${CHECKER_SMTP_PORT:-587}
+        username: checker
+        password: env:CHECKER_SMTP_PASSWORD
+      from: checker@example.com
+      recipients: [ops@example.com]
+`
+	path := filepath.Join(dir, "hosts.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+
+	if want := []string{"staging.example.com", "api.staging.example.com:8443"}; strings.Join(config.Hosts, ",") != strings.Join(want, ",") {
+		t.Errorf("LoadConfig() hosts = %v, want %v", config.Hosts, want)
+	}
+	if tags := config.Tags["staging.example.com"]; len(tags) != 1 || tags[0] != "staging" {
+		t.Errorf("LoadConfig() tags = %v, want [staging]", config.Tags)
+	}
+	var alerts notify.Config
+	if err := config.DecodeAlerts(&alerts); err != nil {
+		t.Fatalf("DecodeAlerts() unexpected error: %v", err)
+	}
+	if err := alerts.ResolveSecrets(ResolveSecret); err != nil {
+		t.Fatalf("ResolveSecrets() unexpected error: %v", err)
+	}
+	if key := alerts.Events[0].RoutingKey; key != "R0UT1NG" {
+		t.Errorf("LoadConfig() routing key = %q, want the content of the secret file", key)
+	}
+	smtpConfig := alerts.Email[0].SMTP
+	if smtpConfig.Port != 587 || smtpConfig.Password != "p4ss" {
+		t.Errorf("LoadConfig() smtp = %+v, want port 587 and the password from the environment", smtpConfig)
+	}
+
+	// Undefined variables point to their line
+	if err := os.WriteFile(path, []byte("hosts:\n  - example.com\n  - ${CHECKER_UNSET}.example.com\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), "undefined variable CHECKER_UNSET at "+path+":3") {
+		t.Errorf("LoadConfig() error = %v, want the undefined variable and its line", err)
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+	FormatTOML = "toml"
+	FormatText = "text"
+)
+
+// tomlLine matches the table headers and key/value pairs of TOML files
+var tomlLine = regexp.MustCompile(`^(\[\[?\s*[A-Za-z0-9_"'.-]+\s*\]\]?|[A-Za-z0-9_"'.-]+\s*=)`)
+
+// DetectFormat returns the format of a config file from its extension, or
+// from its content when the extension is not a known one
+func DetectFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".json":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	case ".txt", ".list":
+		return FormatText
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return FormatJSON
+	}
+
+	// The first line that is not blank or a comment tells TOML apart, every
+	// line has to be a host for plain text
+	text := true
+	for i, line := range meaningfulLines(string(data)) {
+		if i == 0 && tomlLine.MatchString(line) {
+			return FormatTOML
+		}
+		if host, _, _ := strings.Cut(line, "#"); !isHostLine(strings.TrimSpace(host)) {
+			text = false
+		}
+	}
+	if text {
+		return FormatText
+	}
+
+	return FormatYAML
+}
+
+// isHostLine reports whether a line of a config file looks like a host of a
+// plain text file rather than YAML
+func isHostLine(line string) bool {
+	if line == "" || strings.ContainsAny(line, " \t") || strings.HasSuffix(line, ":") {
+		return false
+	}
+
+	// Bracketed IPv6 addresses are hosts rather than YAML flow sequences
+	if strings.HasPrefix(line, "[") {
+		address, _, _ := strings.Cut(line[1:], "]")
+		return net.ParseIP(address) != nil && validateHost(line) == nil
+	}
+
+	return !strings.ContainsAny(line[:1], "-{\"'")
+}
+
+// meaningfulLines returns the lines that are not blank or comments, trimmed
+func meaningfulLines(data string) []string {
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// parseDocument parses a config file in the given format, detected when
+// empty, into the YAML document the config is decoded from, keeping the line
+// of every value
+func parseDocument(path string, data []byte, format string) (*yaml.Node, error) {
+	if format == "" {
+		format = DetectFormat(path, data)
+	}
+
+	switch format {
+	case FormatTOML:
+		root, err := parseTOML(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid TOML format in %s: %w", path, err)
+		}
+		return root, nil
+
+	case FormatText:
+		return parseText(string(data)), nil
+
+	default:
+		var root yaml.Node
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, fmt.Errorf("invalid %s format in %s: %w", strings.ToUpper(format), path, err)
+		}
+		return &root, nil
+	}
+}
+
+// parseText parses a plain text file listing one host per line, with blank
+// lines and comments starting with # ignored
+func parseText(data string) *yaml.Node {
+	hosts := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for i, line := range strings.Split(data, "\n") {
+		if comment := strings.Index(line, "#"); comment >= 0 {
+			line = line[:comment]
+		}
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		hosts.Content = append(hosts.Content, stringNode(line, i+1))
+	}
+
+	mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1}
+	mapping.Content = append(mapping.Content, stringNode("hosts", 1), hosts)
+
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{mapping}, Line: 1}
+}
+
+// stringNode returns a quoted string node, so its value is never resolved
+// to another type
+func stringNode(value string, line int) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle, Value: value, Line: line}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{name: "yaml extension", path: "hosts.yml", content: "example.com\n", want: FormatYAML},
+		{name: "json extension", path: "hosts.json", content: "", want: FormatJSON},
+		{name: "toml extension", path: "hosts.TOML", content: "", want: FormatTOML},
+		{name: "text extension", path: "hosts.txt", content: "hosts:\n", want: FormatText},
+		{name: "json content", path: "inventory", content: "\n  {\"hosts\": [\"example.com\"]}", want: FormatJSON},
+		{name: "toml content", path: "hosts.conf", content: "# hosts\nhosts = [\"example.com\"]\n", want: FormatTOML},
+		{name: "toml table", path: "hosts.conf", content: "[tags]\n", want: FormatTOML},
+		{name: "text content", path: "hosts", content: "# web\nexample.com\napi.example.com:8443 # api\n\n", want: FormatText},
+		{name: "yaml content", path: "hosts", content: "hosts:\n  - example.com\n", want: FormatYAML},
+		{name: "yaml flow content", path: "hosts", content: "hosts: [example.com]\n", want: FormatYAML},
+		{name: "text content with ipv6", path: "hosts", content: "[2001:db8::1]:8443\n[::1]\nexample.com\n", want: FormatText},
+		{name: "yaml flow sequence", path: "hosts", content: "[example.com,api.example.com]\n", want: FormatYAML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFormat(tt.path, []byte(tt.content)); got != tt.want {
+				t.Errorf("DetectFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Formats(t *testing.T) {
+	files := map[string]string{
+		"hosts.yaml": `hosts:
+  - example.com
+  - api.example.com:8443
+tags:
+  api.example.com:8443: [production, api]
+`,
+		"hosts.json": `{
+  "hosts": [
+    "example.com",
+    "api.example.com:8443"
+  ],
+  "tags": {"api.example.com:8443": ["production", "api"]}
+}
+`,
+		"hosts.toml": `# inventory
+hosts = [
+  "example.com",
+  "api.example.com:8443",
+]
+
+[tags]
+"api.example.com:8443" = ["production", "api"]
+`,
+		"hosts.txt": `# web
+example.com
+api.example.com:8443   # production API
+`,
+	}
+	wantLines := map[string][2]int{
+		"hosts.yaml": {2, 3},
+		"hosts.json": {3, 4},
+		"hosts.toml": {3, 4},
+		"hosts.txt":  {2, 3},
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			config, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig() unexpected error: %v", err)
+			}
+
+			if want := []string{"example.com", "api.example.com:8443"}; !reflect.DeepEqual(config.Hosts, want) {
+				t.Errorf("LoadConfig() hosts = %v, want %v", config.Hosts, want)
+			}
+			if name != "hosts.txt" {
+				if want := map[string][]string{"api.example.com:8443": {"production", "api"}}; !reflect.DeepEqual(config.Tags, want) {
+					t.Errorf("LoadConfig() tags = %v, want %v", config.Tags, want)
+				}
+			}
+
+			lines := wantLines[name]
+			for i, host := range config.Hosts {
+				if source := config.Sources[host]; source.Line != lines[i] {
+					t.Errorf("LoadConfig() line of %s = %d, want %d", host, source.Line, lines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfig_TextIPv6(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte("[2001:db8::1]:8443\nexample.com\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+	if want := []string{"[2001:db8::1]:8443", "example.com"}; !reflect.DeepEqual(config.Hosts, want) {
+		t.Errorf("LoadConfig() hosts = %v, want %v", config.Hosts, want)
+	}
+}
+
+func TestLoadConfig_FormatErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{name: "invalid host in json", file: "hosts.json", content: "{\n  \"hosts\": [\n    \"example.com:99999\"\n  ]\n}\n", wantErr: "hosts.json:3"},
+		{name: "invalid host in toml", file: "hosts.toml", content: "hosts = [\n  \"example.com\",\n  \"example.com:0\",\n]\n", wantErr: "hosts.toml:3"},
+		{name: "invalid host in text", file: "hosts.txt", content: "example.com\n\n# broken\nexample.com:http\n", wantErr: "hosts.txt:4"},
+		{name: "invalid json", file: "hosts.json", content: "{\"hosts\": [\"example.com\"\n", wantErr: "invalid JSON format"},
+		{name: "invalid toml", file: "hosts.toml", content: "hosts = [\"example.com\"]\nport = \n", wantErr: "invalid TOML format"},
+		{name: "wrong type in toml", file: "hosts.toml", content: "hosts = \"example.com\"\n", wantErr: "invalid config"},
+		{name: "no hosts in text", file: "hosts.txt", content: "# nothing yet\n", wantErr: "no hosts found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			_, err := LoadConfig(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
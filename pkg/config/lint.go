@@ -0,0 +1,192 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"go.yaml.in/yaml/v3"
+)
+
+// domainsName names the --domains flag in sources
+const domainsName = "--domains"
+
+// Lint loads the host configuration like GetHostConfig, without stopping at
+// the first problem, and reports the effective host list along with every
+// problem found: invalid files and hosts, unknown keys, hosts defined more
+// than once, even spelled differently, and private addresses when
+// DisallowPrivate is set
+func (c *AppConfig) Lint() *LintReport {
+	l := newLoader()
+	l.lint = true
+
+	if len(c.HostFiles()) > 0 {
+		_ = l.report(l.loadAll(c.ConfigFiles))
+		if c.HostsFrom != "" {
+			_ = l.report(l.load(c.HostsFrom, FormatText))
+		}
+		if c.HostsCSV != "" {
+			options := CSVOptions{HostColumn: c.HostColumn, PortColumn: c.PortColumn, TagColumns: c.TagColumns}
+			_ = l.report(l.loadCSV(c.HostsCSV, options))
+		}
+		_, _ = l.finish()
+	} else {
+		// Domains are located by their position in the list
+		for i, domain := range strings.Split(c.Domains, ",") {
+			if domain = strings.TrimSpace(domain); domain != "" {
+				_ = l.report(l.addHost(domain, Source{File: domainsName, Line: i + 1}))
+			}
+		}
+		if len(l.config.Hosts) == 0 {
+			_ = l.report(fmt.Errorf("no valid domains found in the provided string"))
+		}
+	}
+
+	report := &LintReport{Config: l.config, Problems: l.problems}
+	index := make(map[string]int)
+	firsts := make(map[string]definition)
+	for _, def := range l.definitions {
+		host, err := cert.NormalizeHost(def.host)
+		if err != nil {
+			report.Problems = append(report.Problems, fmt.Errorf("invalid host at %s: %w", def.source, err))
+			continue
+		}
+
+		if first, ok := firsts[host]; ok {
+			if def.host == first.host {
+				report.Problems = append(report.Problems, fmt.Errorf("duplicate host %s at %s, already defined at %s", def.host, def.source, first.source))
+			} else {
+				report.Problems = append(report.Problems, fmt.Errorf("duplicate host %s at %s, already defined as %s at %s", def.host, def.source, first.host, first.source))
+			}
+
+			// The tags of every spelling apply to the host
+			i := index[host]
+			for _, tag := range l.config.Tags[def.host] {
+				if !slices.Contains(report.Hosts[i].Tags, tag) {
+					report.Hosts[i].Tags = append(report.Hosts[i].Tags, tag)
+				}
+			}
+			continue
+		}
+
+		if hostname, _, err := net.SplitHostPort(host); err == nil && c.DisallowPrivate && privateAddress(hostname) {
+			report.Problems = append(report.Problems, fmt.Errorf("private address %s at %s", def.host, def.source))
+		}
+
+		firsts[host] = def
+		index[host] = len(report.Hosts)
+		report.Hosts = append(report.Hosts, LintHost{
+			Host:   host,
+			Source: def.source,
+			Tags:   slices.Clone(l.config.Tags[def.host]),
+			Labels: l.config.Labels[def.host],
+		})
+	}
+
+	return report
+}
+
+// privateAddress reports whether a hostname is localhost or a loopback,
+// private, link-local or unspecified IP address, which are not reachable
+// from everywhere
+func privateAddress(hostname string) bool {
+	if strings.EqualFold(hostname, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(hostname)
+	return ip != nil && (ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified())
+}
+
+// UnknownKeys reports the keys of a parsed config file section that do not
+// match a field of out, the value it is decoded into
+func UnknownKeys(node *yaml.Node, out any, name string) []error {
+	return unknownKeys(node, reflect.TypeOf(out), name)
+}
+
+// unknownKeys reports the keys of a parsed config file that do not match a
+// field of the type its values are decoded into, values kept as parsed are
+// not checked
+func unknownKeys(node *yaml.Node, typ reflect.Type, name string) []error {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == reflect.TypeFor[yaml.Node]() {
+		return nil
+	}
+
+	var errs []error
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			errs = append(errs, unknownKeys(child, typ, name)...)
+		}
+
+	case yaml.SequenceNode:
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			for _, child := range node.Content {
+				errs = append(errs, unknownKeys(child, typ.Elem(), name)...)
+			}
+		}
+
+	case yaml.MappingNode:
+		var fields map[string]reflect.Type
+		if typ.Kind() == reflect.Struct {
+			fields = yamlFields(typ)
+		} else if typ.Kind() != reflect.Map {
+			return nil
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if fields == nil {
+				errs = append(errs, unknownKeys(value, typ.Elem(), name)...)
+				continue
+			}
+
+			field, ok := fields[key.Value]
+			if !ok {
+				errs = append(errs, fmt.Errorf("unknown key %q at %s", key.Value, Source{File: name, Line: key.Line}))
+				continue
+			}
+			errs = append(errs, unknownKeys(value, field, name)...)
+		}
+	}
+
+	return errs
+}
+
+// yamlFields returns the type of the fields of a struct by YAML key, those
+// of inline structs included
+func yamlFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if key == "-" {
+			continue
+		}
+		if slices.Contains(strings.Split(options, ","), "inline") {
+			inline := field.Type
+			if inline.Kind() == reflect.Pointer {
+				inline = inline.Elem()
+			}
+			maps.Copy(fields, yamlFields(inline))
+			continue
+		}
+
+		if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+		fields[key] = field.Type
+	}
+	return fields
+}
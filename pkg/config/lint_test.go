@@ -0,0 +1,129 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAppConfig_Lint(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"hosts.yaml": `hosts:
+  - example.com
+  - Example.com:443
+  - 10.0.0.5
+  - example.com:99999
+  - api.example.com:8443
+hostz:
+  - typo.example.com
+tags:
+  example.com: [web]
+  Example.com:443: [shared]
+  ghost.example.com: [legacy]
+include: [team.yaml, missing/*.yaml]
+alerts:
+  webhooks:
+    - type: slack
+      url: https://hooks.slack.com/services/T000/B000/XXX
+      min_status: warning
+      urll: https://example.com
+`,
+		"team.yaml":     "hosts:\n  - example.com\n  - localhost:8443\n",
+		"inventory.csv": "host,team\nstatus.example.com,web\nexample.com:99999,db\n",
+	})
+
+	appConfig := AppConfig{
+		ConfigFiles:     []string{filepath.Join(dir, "hosts.yaml")},
+		HostsCSV:        filepath.Join(dir, "inventory.csv"),
+		TagColumns:      []string{"team"},
+		DisallowPrivate: true,
+	}
+	report := appConfig.Lint()
+
+	var hosts []string
+	for _, host := range report.Hosts {
+		hosts = append(hosts, host.Host)
+	}
+	wantHosts := []string{"example.com:443", "10.0.0.5:443", "api.example.com:8443", "localhost:8443", "status.example.com:443"}
+	if !reflect.DeepEqual(hosts, wantHosts) {
+		t.Errorf("Lint() hosts = %v, want %v", hosts, wantHosts)
+	}
+	if tags := report.Hosts[0].Tags; !reflect.DeepEqual(tags, []string{"web", "shared"}) {
+		t.Errorf("Lint() tags = %v, want the tags of both spellings", tags)
+	}
+	if labels := report.Hosts[4].Labels; labels["team"] != "web" {
+		t.Errorf("Lint() labels = %v, want the CSV labels", labels)
+	}
+
+	wantProblems := []string{
+		`unknown key "hostz" at ` + filepath.Join(dir, "hosts.yaml") + ":7",
+		"hosts.yaml:5: port number out of range",
+		"invalid include in",
+		"inventory.csv:3: port number out of range",
+		"tags defined for unknown host: ghost.example.com",
+		"duplicate host Example.com:443 at " + filepath.Join(dir, "hosts.yaml") + ":3, already defined as example.com at " + filepath.Join(dir, "hosts.yaml") + ":2",
+		"private address 10.0.0.5",
+		"duplicate host example.com at " + filepath.Join(dir, "team.yaml") + ":2, already defined at",
+		"private address localhost:8443",
+	}
+	if len(report.Problems) != len(wantProblems) {
+		t.Errorf("Lint() problems = %v, want %d problems", report.Problems, len(wantProblems))
+	}
+	for i, want := range wantProblems {
+		if i >= len(report.Problems) || !strings.Contains(report.Problems[i].Error(), want) {
+			t.Errorf("Lint() problem %d = %v, want it to contain %q", i, report.Problems, want)
+		}
+	}
+}
+
+func TestAppConfig_Lint_Domains(t *testing.T) {
+	tests := []struct {
+		name         string
+		appConfig    AppConfig
+		wantHosts    int
+		wantProblems []string
+	}{
+		{
+			name:      "valid domains",
+			appConfig: AppConfig{Domains: "example.com, example.org:8443"},
+			wantHosts: 2,
+		},
+		{
+			name:         "duplicate and invalid domains",
+			appConfig:    AppConfig{Domains: "example.com,example.com:99999,EXAMPLE.com:443"},
+			wantHosts:    1,
+			wantProblems: []string{"invalid host at --domains:2", "duplicate host EXAMPLE.com:443 at --domains:3"},
+		},
+		{
+			name:      "private addresses allowed",
+			appConfig: AppConfig{Domains: "127.0.0.1,[fe80::1]:8443"},
+			wantHosts: 2,
+		},
+		{
+			name:         "private addresses disallowed",
+			appConfig:    AppConfig{Domains: "127.0.0.1,[fe80::1]:8443,8.8.8.8", DisallowPrivate: true},
+			wantHosts:    3,
+			wantProblems: []string{"private address 127.0.0.1", "private address [fe80::1]:8443"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := tt.appConfig.Lint()
+
+			if len(report.Hosts) != tt.wantHosts {
+				t.Errorf("Lint() hosts = %+v, want %d hosts", report.Hosts, tt.wantHosts)
+			}
+			if len(report.Problems) != len(tt.wantProblems) {
+				t.Fatalf("Lint() problems = %v, want %v", report.Problems, tt.wantProblems)
+			}
+			for i, want := range tt.wantProblems {
+				if !strings.Contains(report.Problems[i].Error(), want) {
+					t.Errorf("Lint() problem %d = %v, want it to contain %q", i, report.Problems[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package config
+
+// LintReport is the outcome of linting the host configuration
+type LintReport struct {
+	// Config is the merged config, as far as it could be loaded
+	Config *Config
+	// Hosts is the effective host list, normalized to hostname:port
+	Hosts []LintHost
+	// Problems lists every problem found, those of the files in the order
+	// they are loaded first
+	Problems []error
+}
+
+// LintHost is a host of the effective host list
+type LintHost struct {
+	Host   string
+	Source Source
+	Tags   []string
+	Labels map[string]string
+}
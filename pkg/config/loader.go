@@ -0,0 +1,444 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	// configVersionLength is the number of hex digits of a config version
+	configVersionLength = 12
+	// stdinPath reads a config file from stdin
+	stdinPath = "-"
+	// stdinName names stdin in messages and sources
+	stdinName = "stdin"
+)
+
+// stdin is read at most once, loading a config from it again yields the
+// same content
+var stdin = &stdinReader{reader: os.Stdin}
+
+// LoadConfigs loads the config files matching the given paths or glob
+// patterns along with the files they include. Hosts are merged in order,
+// a host defined more than once keeps its first definition.
+func LoadConfigs(patterns []string) (*Config, error) {
+	l := newLoader()
+	if err := l.loadAll(patterns); err != nil {
+		return nil, err
+	}
+
+	return l.finish()
+}
+
+// newLoader returns a loader of an empty config
+func newLoader() *loader {
+	return &loader{
+		config:  &Config{Sources: make(map[string]Source)},
+		digest:  sha256.New(),
+		loading: make(map[string]bool),
+		loaded:  make(map[string]bool),
+	}
+}
+
+// ConfigFiles returns the config files matching the given paths or glob
+// patterns along with the files they include, skipping the files that
+// cannot be read or parsed
+func ConfigFiles(patterns []string) []string {
+	var files []string
+	seen := make(map[string]bool)
+
+	var visit func(patterns []string, dir string)
+	visit = func(patterns []string, dir string) {
+		paths, _ := expandPatterns(patterns, dir)
+		for _, path := range paths {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			files = append(files, path)
+
+			if path == stdinPath {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			root, err := parseConfigFile(path, data, "")
+			if err != nil {
+				continue
+			}
+			var includes struct {
+				Include []string `yaml:"include"`
+			}
+			if root.Decode(&includes) == nil {
+				visit(includes.Include, filepath.Dir(path))
+			}
+		}
+	}
+	visit(patterns, "")
+
+	return files
+}
+
+// expandPatterns resolves the paths and glob patterns against dir, paths
+// without glob characters are returned as is even when they do not exist
+func expandPatterns(patterns []string, dir string) ([]string, error) {
+	var paths []string
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return nil, fmt.Errorf("config file path cannot be empty")
+		}
+		if dir != "" && !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+
+		if !strings.ContainsAny(pattern, "*?[") {
+			paths = append(paths, pattern)
+			continue
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config file pattern %s: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no config files match %s", pattern)
+		}
+		paths = append(paths, matches...)
+	}
+
+	return paths, nil
+}
+
+// loadAll merges the config files matching the given paths or glob patterns
+func (l *loader) loadAll(patterns []string) error {
+	paths, err := expandPatterns(patterns, "")
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if err := l.load(path, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// load merges a config file of the given format, detected when empty, and
+// then the files it includes
+func (l *loader) load(path, format string) error {
+	key, err := filepath.Abs(path)
+	if err != nil || path == stdinPath {
+		key = path
+	}
+	if l.loading[key] {
+		return l.report(fmt.Errorf("config file %s includes itself", path))
+	}
+	if l.loaded[key] {
+		return nil
+	}
+
+	data, err := l.read(path)
+	if err != nil {
+		return l.report(err)
+	}
+
+	name := sourceName(path)
+	root, err := parseConfigFile(name, data, format)
+	if err != nil {
+		return l.report(err)
+	}
+	if l.lint {
+		l.problems = append(l.problems, unknownKeys(root, reflect.TypeFor[Config](), name)...)
+	}
+
+	// Linting goes on with what could be decoded
+	var file Config
+	if err := root.Decode(&file); err != nil {
+		if err := l.report(fmt.Errorf("invalid config in %s: %w", name, err)); err != nil {
+			return err
+		}
+	}
+	lines := hostLines(root)
+
+	for i, host := range file.Hosts {
+		source := Source{File: name}
+		if i < len(lines) {
+			source.Line = lines[i]
+		}
+		if err := l.report(l.addHost(host, source)); err != nil {
+			return err
+		}
+	}
+
+	for _, host := range slices.Sorted(maps.Keys(file.Tags)) {
+		if err := l.addTags(host, file.Tags[host]); err != nil {
+			if err := l.report(fmt.Errorf("%w in %s", err, name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, host := range slices.Sorted(maps.Keys(file.Labels)) {
+		if err := l.addLabels(host, file.Labels[host]); err != nil {
+			if err := l.report(fmt.Errorf("%w in %s", err, name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if file.Alerts.Kind != 0 {
+		if err := l.report(l.addAlerts(file.Alerts, name)); err != nil {
+			return err
+		}
+	}
+
+	// Patterns are expanded one by one for linting to go on with the others
+	var includes []string
+	for _, pattern := range file.Include {
+		paths, err := expandPatterns([]string{pattern}, filepath.Dir(path))
+		if err != nil {
+			if err := l.report(fmt.Errorf("invalid include in %s: %w", name, err)); err != nil {
+				return err
+			}
+			continue
+		}
+		includes = append(includes, paths...)
+	}
+
+	l.loading[key] = true
+	for _, include := range includes {
+		if err := l.load(include, ""); err != nil {
+			return err
+		}
+	}
+	delete(l.loading, key)
+	l.loaded[key] = true
+
+	return nil
+}
+
+// read returns the content of a config file, or of stdin for -
+func (l *loader) read(path string) ([]byte, error) {
+	var data []byte
+	if path == stdinPath {
+		var err error
+		if data, err = stdin.read(); err != nil {
+			return nil, fmt.Errorf("cannot read config from stdin: %w", err)
+		}
+	} else {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, fmt.Errorf("config file does not exist: %s", path)
+		}
+
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("cannot read config file: %w", err)
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("config file is empty: %s", sourceName(path))
+	}
+
+	l.digest.Write(data)
+	l.config.Files = append(l.config.Files, path)
+
+	return data, nil
+}
+
+// addHost adds a host defined at the source, unless it was already added
+func (l *loader) addHost(host string, source Source) error {
+	if err := validateHost(host); err != nil {
+		return fmt.Errorf("invalid host at %s: %w", source, err)
+	}
+	l.definitions = append(l.definitions, definition{host: host, source: source})
+
+	if _, ok := l.config.Sources[host]; ok {
+		return nil
+	}
+	l.config.Hosts = append(l.config.Hosts, host)
+	l.config.Sources[host] = source
+
+	return nil
+}
+
+// addAlerts sets the alerts defined in a config file, they can only be
+// defined in one file
+func (l *loader) addAlerts(alerts yaml.Node, name string) error {
+	if l.config.AlertsFile != "" {
+		return fmt.Errorf("alerts defined in both %s and %s", l.config.AlertsFile, name)
+	}
+	l.config.Alerts = alerts
+	l.config.AlertsFile = name
+
+	return nil
+}
+
+// addTags adds tags to a host, keeping the tags it already has
+func (l *loader) addTags(host string, tags []string) error {
+	if slices.Contains(tags, "") {
+		return fmt.Errorf("empty tag for host %s", host)
+	}
+
+	if l.config.Tags == nil {
+		l.config.Tags = make(map[string][]string)
+	}
+	for _, tag := range tags {
+		if !slices.Contains(l.config.Tags[host], tag) {
+			l.config.Tags[host] = append(l.config.Tags[host], tag)
+		}
+	}
+
+	return nil
+}
+
+// addLabels adds labels to a host, labels it already has keep their value
+func (l *loader) addLabels(host string, labels map[string]string) error {
+	if l.config.Labels == nil {
+		l.config.Labels = make(map[string]map[string]string)
+	}
+
+	for name, value := range labels {
+		if name == "" {
+			return fmt.Errorf("empty label name for host %s", host)
+		}
+
+		if l.config.Labels[host] == nil {
+			l.config.Labels[host] = make(map[string]string)
+		}
+		if _, ok := l.config.Labels[host][name]; !ok {
+			l.config.Labels[host][name] = value
+		}
+	}
+
+	return nil
+}
+
+// report returns err, unless linting where err is recorded and nil is
+// returned for loading to go on
+func (l *loader) report(err error) error {
+	if err == nil || !l.lint {
+		return err
+	}
+	l.problems = append(l.problems, err)
+	return nil
+}
+
+// sourceName returns the name of a config file in messages and sources
+func sourceName(path string) string {
+	if path == stdinPath {
+		return stdinName
+	}
+	return path
+}
+
+// finish validates the merged config
+func (l *loader) finish() (*Config, error) {
+	config := l.config
+
+	if len(config.Hosts) == 0 {
+		if err := l.report(fmt.Errorf("no hosts found in config file")); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, host := range slices.Sorted(maps.Keys(config.Tags)) {
+		if !slices.Contains(config.Hosts, host) {
+			if err := l.report(fmt.Errorf("tags defined for unknown host: %s", host)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, host := range slices.Sorted(maps.Keys(config.Labels)) {
+		if !slices.Contains(config.Hosts, host) {
+			if err := l.report(fmt.Errorf("labels defined for unknown host: %s", host)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	config.Version = hex.EncodeToString(l.digest.Sum(nil))[:configVersionLength]
+
+	return config, nil
+}
+
+// hostLines returns the line of every entry of the hosts list of a parsed
+// config file
+func hostLines(root *yaml.Node) []int {
+	var lines []int
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return lines
+	}
+
+	mapping := root.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return lines
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if key.Value != "hosts" || value.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, entry := range value.Content {
+			lines = append(lines, entry.Line)
+		}
+	}
+
+	return lines
+}
+
+// HasAlerts reports whether a config file defines alerts
+func (c *Config) HasAlerts() bool {
+	return c.AlertsFile != ""
+}
+
+// DecodeAlerts decodes the alerts section into out, which is left untouched
+// when no config file defines alerts
+func (c *Config) DecodeAlerts(out any) error {
+	if !c.HasAlerts() {
+		return nil
+	}
+	if err := c.Alerts.Decode(out); err != nil {
+		return fmt.Errorf("invalid alerts configuration in %s: %w", c.AlertsFile, err)
+	}
+	return nil
+}
+
+// HostSources returns the location of the definition of every host, as
+// file:line
+func (c *Config) HostSources() map[string]string {
+	sources := make(map[string]string, len(c.Sources))
+	for host, source := range c.Sources {
+		sources[host] = source.String()
+	}
+	return sources
+}
+
+// String returns the location as file:line
+func (s Source) String() string {
+	return fmt.Sprintf("%s:%d", s.File, s.Line)
+}
+
+// read returns the content of stdin, read on the first call
+func (s *stdinReader) read() ([]byte, error) {
+	s.once.Do(func() {
+		s.data, s.err = io.ReadAll(s.reader)
+	})
+	return s.data, s.err
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfigFiles writes the given files below dir, creating directories
+func writeConfigFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+	}
+}
+
+func TestLoadConfigs(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"hosts.yaml":         "hosts:\n  - example.com\n  - api.example.com:8443\ninclude:\n  - shared/*.yaml\ntags:\n  example.com: [web]\n",
+		"shared/common.yaml": "# shared hosts\nhosts:\n  - example.com\n  - status.example.com\ntags:\n  example.com: [shared]\n",
+		"conf.d/a.yaml":      "hosts:\n  - a.example.com\n",
+		"conf.d/b.yaml":      "hosts:\n  - b.example.com\n  - a.example.com\n",
+	})
+
+	config, err := LoadConfigs([]string{filepath.Join(dir, "hosts.yaml"), filepath.Join(dir, "conf.d", "*.yaml")})
+	if err != nil {
+		t.Fatalf("LoadConfigs() unexpected error: %v", err)
+	}
+
+	wantHosts := []string{"example.com", "api.example.com:8443", "status.example.com", "a.example.com", "b.example.com"}
+	if !reflect.DeepEqual(config.Hosts, wantHosts) {
+		t.Errorf("LoadConfigs() hosts = %v, want %v", config.Hosts, wantHosts)
+	}
+
+	wantSources := map[string]string{
+		"example.com":          filepath.Join(dir, "hosts.yaml") + ":2",
+		"api.example.com:8443": filepath.Join(dir, "hosts.yaml") + ":3",
+		"status.example.com":   filepath.Join(dir, "shared", "common.yaml") + ":4",
+		"a.example.com":        filepath.Join(dir, "conf.d", "a.yaml") + ":2",
+		"b.example.com":        filepath.Join(dir, "conf.d", "b.yaml") + ":2",
+	}
+	if sources := config.HostSources(); !reflect.DeepEqual(sources, wantSources) {
+		t.Errorf("LoadConfigs() sources = %v, want %v", sources, wantSources)
+	}
+
+	if tags := config.Tags["example.com"]; !reflect.DeepEqual(tags, []string{"web", "shared"}) {
+		t.Errorf("LoadConfigs() tags = %v, want the tags of both files", tags)
+	}
+
+	if len(config.Files) != 4 {
+		t.Errorf("LoadConfigs() files = %v, want 4 files", config.Files)
+	}
+	if files := ConfigFiles([]string{filepath.Join(dir, "hosts.yaml"), filepath.Join(dir, "conf.d", "*.yaml")}); !reflect.DeepEqual(files, config.Files) {
+		t.Errorf("ConfigFiles() = %v, want %v", files, config.Files)
+	}
+}
+
+func TestLoadConfigs_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "invalid host points to its line",
+			files:   map[string]string{"hosts.yaml": "hosts:\n  - example.com\n  - example.com:99999\n"},
+			wantErr: "hosts.yaml:3",
+		},
+		{
+			name:    "invalid host in an included file",
+			files:   map[string]string{"hosts.yaml": "include: [team.yaml]\nhosts: [example.com]\n", "team.yaml": "hosts:\n  - \"\"\n"},
+			wantErr: "team.yaml:2",
+		},
+		{
+			name:    "include cycle",
+			files:   map[string]string{"hosts.yaml": "include: [team.yaml]\nhosts: [example.com]\n", "team.yaml": "include: [hosts.yaml]\n"},
+			wantErr: "includes itself",
+		},
+		{
+			name:    "include matching nothing",
+			files:   map[string]string{"hosts.yaml": "include: [conf.d/*.yaml]\nhosts: [example.com]\n"},
+			wantErr: "no config files match",
+		},
+		{
+			name:    "alerts defined twice",
+			files:   map[string]string{"hosts.yaml": "include: [team.yaml]\nhosts: [example.com]\nalerts: {}\n", "team.yaml": "alerts: {}\n"},
+			wantErr: "alerts defined in both",
+		},
+		{
+			name:    "tags for a host of another file",
+			files:   map[string]string{"hosts.yaml": "include: [team.yaml]\nhosts: [example.com]\n", "team.yaml": "tags:\n  example.com: [team]\n"},
+			wantErr: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeConfigFiles(t, dir, tt.files)
+
+			_, err := LoadConfigs([]string{filepath.Join(dir, "hosts.yaml")})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("LoadConfigs() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfigs() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
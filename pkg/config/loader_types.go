@@ -0,0 +1,38 @@
+package config
+
+import (
+	"hash"
+	"io"
+	"sync"
+)
+
+// loader merges config files and the files they include into one config
+type loader struct {
+	config *Config
+	digest hash.Hash
+	// loading holds the files being loaded, to detect include cycles
+	loading map[string]bool
+	// loaded holds the files already merged, to merge each file once
+	loaded map[string]bool
+	// definitions lists every valid definition of a host, duplicates included
+	definitions []definition
+
+	// lint records problems in problems and goes on loading, instead of
+	// stopping at the first one
+	lint     bool
+	problems []error
+}
+
+// definition locates a host in the config files
+type definition struct {
+	host   string
+	source Source
+}
+
+// stdinReader reads stdin once and keeps its content
+type stdinReader struct {
+	reader io.Reader
+	once   sync.Once
+	data   []byte
+	err    error
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"go.yaml.in/yaml/v3"
+)
+
+// parseTOML parses a TOML document into a YAML document, so TOML config files
+// are decoded like YAML ones. The document is validated by the TOML decoder,
+// then converted from its syntax tree to keep the line of every value.
+func parseTOML(src string) (*yaml.Node, error) {
+	var document map[string]any
+	if err := toml.Unmarshal([]byte(src), &document); err != nil {
+		return nil, tomlError(err)
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1}
+	b := &tomlBuilder{root: root, table: root}
+	b.parser.Reset([]byte(src))
+	for b.parser.NextExpression() {
+		b.expression(b.parser.Expression())
+	}
+	if err := b.parser.Error(); err != nil {
+		return nil, err
+	}
+
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}, Line: 1}, nil
+}
+
+// tomlError returns the error of the TOML decoder along with its line, when
+// the decoder knows it
+func tomlError(err error) error {
+	var decodeErr *toml.DecodeError
+	if errors.As(err, &decodeErr) {
+		line, _ := decodeErr.Position()
+		return fmt.Errorf("line %d: %s", line, strings.TrimPrefix(decodeErr.Error(), "toml: "))
+	}
+	return errors.New(strings.TrimPrefix(err.Error(), "toml: "))
+}
+
+// expression adds a table header or a key/value pair to the document
+func (b *tomlBuilder) expression(expr *unstable.Node) {
+	switch expr.Kind {
+	case unstable.Table:
+		b.table = b.root
+		for _, key := range keyParts(expr) {
+			b.table = b.child(b.table, key)
+		}
+
+	case unstable.ArrayTable:
+		keys := keyParts(expr)
+		parent := b.root
+		for _, key := range keys[:len(keys)-1] {
+			parent = b.child(parent, key)
+		}
+
+		last := keys[len(keys)-1]
+		line := b.line(last, parent.Line)
+		tables := lookup(parent, string(last.Data))
+		if tables == nil {
+			tables = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: line}
+			parent.Content = append(parent.Content, stringNode(string(last.Data), line), tables)
+		}
+		b.table = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line}
+		tables.Content = append(tables.Content, b.table)
+
+	case unstable.KeyValue:
+		b.keyValue(b.table, expr)
+	}
+}
+
+// keyValue adds a key/value pair to table, a dotted key defines the tables
+// along the way
+func (b *tomlBuilder) keyValue(table *yaml.Node, kv *unstable.Node) {
+	keys := keyParts(kv)
+	for _, key := range keys[:len(keys)-1] {
+		table = b.child(table, key)
+	}
+
+	last := keys[len(keys)-1]
+	line := b.line(last, table.Line)
+	table.Content = append(table.Content, stringNode(string(last.Data), line), b.value(kv.Value(), line))
+}
+
+// child returns the table under key, created when missing. An array of
+// tables stands for its last table.
+func (b *tomlBuilder) child(table *yaml.Node, key *unstable.Node) *yaml.Node {
+	name := string(key.Data)
+	if child := lookup(table, name); child != nil {
+		if child.Kind == yaml.SequenceNode {
+			return child.Content[len(child.Content)-1]
+		}
+		return child
+	}
+
+	line := b.line(key, table.Line)
+	child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line}
+	table.Content = append(table.Content, stringNode(name, line), child)
+	return child
+}
+
+// value converts a value of the syntax tree, line is the line of its key
+func (b *tomlBuilder) value(value *unstable.Node, line int) *yaml.Node {
+	line = b.line(value, line)
+	data := string(value.Data)
+
+	switch value.Kind {
+	case unstable.Array:
+		array := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle, Line: line}
+		for it := value.Children(); it.Next(); {
+			array.Content = append(array.Content, b.value(it.Node(), line))
+		}
+		return array
+
+	case unstable.InlineTable:
+		table := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Style: yaml.FlowStyle, Line: line}
+		for it := value.Children(); it.Next(); {
+			b.keyValue(table, it.Node())
+		}
+		return table
+
+	case unstable.Bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: data, Line: line}
+
+	case unstable.Integer:
+		// The decoder already checked the number, including its base prefix
+		n, _ := strconv.ParseInt(strings.ReplaceAll(data, "_", ""), 0, 64)
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.FormatInt(n, 10), Line: line}
+
+	case unstable.Float:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: tomlFloat(data), Line: line}
+
+	case unstable.LocalDate, unstable.LocalDateTime, unstable.DateTime:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: tomlTimestamp(value.Kind, data), Line: line}
+	}
+
+	return stringNode(data, line)
+}
+
+// tomlFloat returns a TOML float in the YAML syntax
+func tomlFloat(number string) string {
+	switch number {
+	case "inf", "+inf":
+		return ".inf"
+	case "-inf":
+		return "-.inf"
+	case "nan", "+nan", "-nan":
+		return ".nan"
+	}
+	return strings.ReplaceAll(number, "_", "")
+}
+
+// tomlTimestamp returns a TOML date or date-time in the YAML syntax, which
+// only separates the date and the time with a T when an offset follows
+func tomlTimestamp(kind unstable.Kind, value string) string {
+	value = strings.ToUpper(value)
+	if len(value) <= len("2006-01-02") {
+		return value
+	}
+
+	separator := " "
+	if kind == unstable.DateTime {
+		separator = "T"
+	}
+	return value[:10] + separator + value[11:]
+}
+
+// line returns the line of node in the document, fallback when the syntax
+// tree does not record where the node is
+func (b *tomlBuilder) line(node *unstable.Node, fallback int) int {
+	if node.Raw.Length == 0 {
+		return fallback
+	}
+	return b.parser.Shape(node.Raw).Start.Line
+}
+
+// keyParts returns the parts of the dotted key of a table header or a
+// key/value pair
+func keyParts(node *unstable.Node) []*unstable.Node {
+	var keys []*unstable.Node
+	for it := node.Key(); it.Next(); {
+		keys = append(keys, it.Node())
+	}
+	return keys
+}
+
+// lookup returns the value of a key of a mapping, nil when it has none
+func lookup(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
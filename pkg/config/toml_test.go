@@ -0,0 +1,229 @@
+package config
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/notify"
+)
+
+func TestParseTOML(t *testing.T) {
+	content := `hosts = ["example.com", 'api.example.com:8443'] # inline comment
+include = []
+
+[alerts]
+state_file = "/var/lib/checker/alerts.json"
+
+[[alerts.webhooks]]
+type = "slack"
+url = "https://hooks.example.com/slack"
+min_status = "critical"
+send_resolved = true
+timeout = "5s"
+
+[[alerts.webhooks]]
+name = "inventory"
+type = "generic"
+url = "https://inventory.example.com/hook"
+headers = { Authorization = "Bearer \u0074oken", "X-Team" = "ops" }
+body = """
+{"text": {{json .Text}}}"""
+
+[[alerts.email]]
+from = "certs@example.com"
+recipients = [
+  "ops@example.com",
+  { address = "web@example.com", tags = ["web"] },
+]
+smtp.host = "smtp.example.com"
+smtp.port = 1_025
+`
+
+	root, err := parseTOML(content)
+	if err != nil {
+		t.Fatalf("parseTOML() unexpected error: %v", err)
+	}
+
+	var config Config
+	if err := root.Decode(&config); err != nil {
+		t.Fatalf("Decode() unexpected error: %v", err)
+	}
+
+	if want := []string{"example.com", "api.example.com:8443"}; !reflect.DeepEqual(config.Hosts, want) {
+		t.Errorf("hosts = %v, want %v", config.Hosts, want)
+	}
+
+	var alerts notify.Config
+	if err := config.Alerts.Decode(&alerts); err != nil {
+		t.Fatalf("Decode() unexpected error: %v", err)
+	}
+	if alerts.StateFile != "/var/lib/checker/alerts.json" || len(alerts.Webhooks) != 2 || len(alerts.Email) != 1 {
+		t.Fatalf("alerts = %+v", alerts)
+	}
+
+	slack := alerts.Webhooks[0]
+	if slack.URL != "https://hooks.example.com/slack" || slack.MinStatus != "critical" || !slack.SendResolved || slack.Timeout != 5*time.Second {
+		t.Errorf("first webhook = %+v", slack)
+	}
+
+	generic := alerts.Webhooks[1]
+	if generic.Headers["Authorization"] != "Bearer token" || generic.Headers["X-Team"] != "ops" {
+		t.Errorf("webhook headers = %v", generic.Headers)
+	}
+	if generic.Body != `{"text": {{json .Text}}}` {
+		t.Errorf("webhook body = %q", generic.Body)
+	}
+
+	email := alerts.Email[0]
+	if email.SMTP.Host != "smtp.example.com" || email.SMTP.Port != 1025 {
+		t.Errorf("smtp = %+v", email.SMTP)
+	}
+	if len(email.Recipients) != 2 || email.Recipients[0].Address != "ops@example.com" || !reflect.DeepEqual(email.Recipients[1].Tags, []string{"web"}) {
+		t.Errorf("recipients = %+v", email.Recipients)
+	}
+}
+
+func TestParseTOML_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "missing equals", content: "hosts [\"example.com\"]\n", wantErr: "line 1: expected character ="},
+		{name: "duplicate key", content: "a = 1\n\nb = 2\na = 3\n", wantErr: "key a is already defined"},
+		{name: "unterminated string", content: "a = \"example.com\n", wantErr: "line 1: basic strings cannot have new lines"},
+		{name: "unterminated array", content: "a = [1,\n2\n", wantErr: "expected character ]"},
+		{name: "unterminated header", content: "[alerts\n", wantErr: "line 1: expected character ]"},
+		{name: "value after value", content: "a = 1 2\n", wantErr: "line 1: expected newline"},
+		{name: "invalid escape", content: "a = \"\\q\"\n", wantErr: "invalid escaped character"},
+		{name: "error line", content: "a = 1\n\nb = \"\\q\"\n", wantErr: "line 3: invalid escaped character"},
+		{name: "table over value", content: "a = 1\n[a.b]\n", wantErr: "expected a to be a table"},
+		{name: "array of tables over table", content: "[a]\n[[a]]\n", wantErr: "should be an array table"},
+		{name: "duplicate table", content: "[a]\nb = 1\n\n[a]\nc = 2\n", wantErr: "table a already exists"},
+		{name: "duplicate dotted table", content: "[a.b]\n[a]\n[a.b]\n", wantErr: "table b already exists"},
+		{name: "table over inline table", content: "a = {b = 1}\n[a]\n", wantErr: "key a should be a table"},
+		{name: "table over array of tables", content: "[[a]]\n[a]\n", wantErr: "key a should be a table"},
+		{name: "leading zero", content: "a = 010\n", wantErr: "line 1: expected newline"},
+		{name: "negative leading zero", content: "a = -01\n", wantErr: "leading zero not allowed"},
+		{name: "float leading zero", content: "a = 01.5\n", wantErr: "line 1: expected newline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTOML(tt.content)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseTOML() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseTOML_Strings(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "basic", content: `a = "tab\there \"quoted\""`, want: "tab\there \"quoted\""},
+		{name: "literal", content: `a = 'C:\certs\${HOME}'`, want: `C:\certs\${HOME}`},
+		{name: "multi-line", content: "a = \"\"\"\nfirst\nsecond\"\"\"", want: "first\nsecond"},
+		{name: "line ending backslash", content: "a = \"\"\"one \\\n    two\"\"\"", want: "one two"},
+		{name: "multi-line literal", content: "a = '''\n{{ .Text }}\\n\n'''", want: "{{ .Text }}\\n\n"},
+		{name: "unicode", content: `a = "caf\u00e9 \U0001F512"`, want: "café 🔒"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseTOML(tt.content)
+			if err != nil {
+				t.Fatalf("parseTOML() unexpected error: %v", err)
+			}
+
+			var values map[string]string
+			if err := root.Decode(&values); err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			if values["a"] != tt.want {
+				t.Errorf("parseTOML() a = %q, want %q", values["a"], tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTOML_Numbers(t *testing.T) {
+	tests := []struct {
+		content string
+		want    any
+	}{
+		{content: "a = 0", want: 0},
+		{content: "a = -0", want: 0},
+		{content: "a = +7", want: 7},
+		{content: "a = 1_000", want: 1000},
+		{content: "a = 0x1F", want: 31},
+		{content: "a = 0o17", want: 15},
+		{content: "a = 0b101", want: 5},
+		{content: "a = 0.5", want: 0.5},
+		{content: "a = -1e3", want: -1000.0},
+		{content: "a = 1_000.5", want: 1000.5},
+		{content: "a = inf", want: math.Inf(1)},
+		{content: "a = -inf", want: math.Inf(-1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			root, err := parseTOML(tt.content)
+			if err != nil {
+				t.Fatalf("parseTOML() unexpected error: %v", err)
+			}
+
+			var values map[string]any
+			if err := root.Decode(&values); err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			if values["a"] != tt.want {
+				t.Errorf("parseTOML() a = %#v, want %#v", values["a"], tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTOML_Dates(t *testing.T) {
+	content := `offset = 1979-05-27T07:32:00Z
+space = 1979-05-27 07:32:00-07:00
+local = 1979-05-27T07:32:00
+day = 1979-05-27
+time = 07:32:00
+`
+
+	root, err := parseTOML(content)
+	if err != nil {
+		t.Fatalf("parseTOML() unexpected error: %v", err)
+	}
+
+	var values struct {
+		Offset time.Time `yaml:"offset"`
+		Space  time.Time `yaml:"space"`
+		Local  time.Time `yaml:"local"`
+		Day    time.Time `yaml:"day"`
+		Time   string    `yaml:"time"`
+	}
+	if err := root.Decode(&values); err != nil {
+		t.Fatalf("Decode() unexpected error: %v", err)
+	}
+
+	if want := time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC); !values.Offset.Equal(want) || !values.Local.Equal(want) {
+		t.Errorf("offset = %v, local = %v, want %v", values.Offset, values.Local, want)
+	}
+	if want := time.Date(1979, 5, 27, 14, 32, 0, 0, time.UTC); !values.Space.Equal(want) {
+		t.Errorf("space = %v, want %v", values.Space, want)
+	}
+	if want := time.Date(1979, 5, 27, 0, 0, 0, 0, time.UTC); !values.Day.Equal(want) {
+		t.Errorf("day = %v, want %v", values.Day, want)
+	}
+	if values.Time != "07:32:00" {
+		t.Errorf("time = %q, want 07:32:00", values.Time)
+	}
+}
@@ -0,0 +1,15 @@
+package config
+
+import (
+	"github.com/pelletier/go-toml/v2/unstable"
+	"go.yaml.in/yaml/v3"
+)
+
+// tomlBuilder converts the syntax tree of a valid TOML document into a YAML
+// document
+type tomlBuilder struct {
+	parser unstable.Parser
+	root   *yaml.Node
+	// table receives the key/value pairs below the last table header
+	table *yaml.Node
+}
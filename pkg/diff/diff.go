@@ -0,0 +1,166 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// Load reads a result saved with the json or yaml output format, the format
+// is detected from the content
+func Load(path string) (*cert.Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read result file: %w", err)
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("result file is empty: %s", path)
+	}
+
+	var result cert.Result
+	if data[0] == '{' {
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("invalid JSON result in %s: %w", path, err)
+		}
+	} else if err := yaml.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid YAML result in %s: %w", path, err)
+	}
+
+	return &result, nil
+}
+
+// Compare reports the hosts added to or removed from the current result, and
+// the hosts whose certificate, issuer, key type or failure state changed
+func Compare(base, current *cert.Result) *Report {
+	baseStates := states(base)
+	currentStates := states(current)
+
+	hosts := make([]string, 0, len(baseStates)+len(currentStates))
+	for host := range baseStates {
+		hosts = append(hosts, host)
+	}
+	for host := range currentStates {
+		if _, ok := baseStates[host]; !ok {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+
+	report := &Report{Changes: make([]Change, 0)}
+	for _, host := range hosts {
+		report.Changes = append(report.Changes, compareHost(host, baseStates[host], currentStates[host])...)
+	}
+
+	return report
+}
+
+// CompareStatus reports the hosts with a certificate in both results whose
+// status changed, e.g. crossing the warning or critical threshold. The
+// statuses are evaluated at the times the results were checked.
+func CompareStatus(base, current *cert.Result, thresholds cert.Thresholds) []Change {
+	baseStatuses := make(map[string]cert.Status)
+	for _, certInfo := range base.Certificates {
+		if _, ok := baseStatuses[certInfo.Host]; !ok {
+			baseStatuses[certInfo.Host], _ = thresholds.Evaluate(certInfo, base.CheckedAt)
+		}
+	}
+
+	var changes []Change
+	seen := make(map[string]bool)
+	for _, certInfo := range current.Certificates {
+		old, ok := baseStatuses[certInfo.Host]
+		if !ok || seen[certInfo.Host] {
+			continue
+		}
+		seen[certInfo.Host] = true
+
+		if status, _ := thresholds.Evaluate(certInfo, current.CheckedAt); status != old {
+			changes = append(changes, Change{Host: certInfo.Host, Kind: ChangeStatus, Old: string(old), New: string(status)})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Host < changes[j].Host
+	})
+
+	return changes
+}
+
+// Changed reports whether anything changed
+func (r *Report) Changed() bool {
+	return len(r.Changes) > 0
+}
+
+// compareHost compares the outcomes of a single host, nil when it is missing
+func compareHost(host string, base, current *state) []Change {
+	switch {
+	case base == nil:
+		return []Change{{Host: host, Kind: ChangeAdded, New: current.describe()}}
+	case current == nil:
+		return []Change{{Host: host, Kind: ChangeRemoved, Old: base.describe()}}
+	case current.certificate == nil && base.certificate != nil:
+		return []Change{{Host: host, Kind: ChangeNewError, Old: base.describe(), New: current.err}}
+	case current.certificate != nil && base.certificate == nil:
+		return []Change{{Host: host, Kind: ChangeRecovered, Old: base.err, New: current.describe()}}
+	case current.certificate == nil:
+		// Still failing, possibly for another reason
+		return nil
+	}
+
+	var changes []Change
+	old, updated := base.certificate, current.certificate
+
+	if !old.SameCertificate(*updated) {
+		changes = append(changes, Change{Host: host, Kind: ChangeCertificate, Old: base.describe(), New: current.describe()})
+	}
+	if old.Issuer != updated.Issuer {
+		changes = append(changes, Change{Host: host, Kind: ChangeIssuer, Old: old.Issuer, New: updated.Issuer})
+	}
+	if old.PublicKeyAlgorithm != updated.PublicKeyAlgorithm {
+		changes = append(changes, Change{Host: host, Kind: ChangeKeyType, Old: old.PublicKeyAlgorithm, New: updated.PublicKeyAlgorithm})
+	}
+
+	return changes
+}
+
+// states indexes the outcomes of a result by host, the first outcome of a
+// host wins
+func states(result *cert.Result) map[string]*state {
+	states := make(map[string]*state)
+
+	for _, certInfo := range result.Certificates {
+		if _, ok := states[certInfo.Host]; ok {
+			continue
+		}
+
+		// Results saved before fingerprints were recorded only have serials,
+		// SameCertificate falls back to them
+		version := certInfo.Version()
+		states[certInfo.Host] = &state{certificate: &version}
+	}
+
+	for _, errorInfo := range result.Errors {
+		if _, ok := states[errorInfo.Host]; !ok {
+			states[errorInfo.Host] = &state{err: errorInfo.Error}
+		}
+	}
+
+	return states
+}
+
+// describe summarizes the outcome of a host
+func (s *state) describe() string {
+	if s.certificate == nil {
+		return s.err
+	}
+	return fmt.Sprintf("serial %s, expiring %s", s.certificate.SerialNumber, s.certificate.NotAfter.UTC().Format(time.DateOnly))
+}
@@ -0,0 +1,174 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/output"
+)
+
+func TestCompare(t *testing.T) {
+	notAfter := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	renewed := notAfter.AddDate(0, 3, 0)
+
+	base := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{Host: "same.example.com:443", SerialNumber: "01", Fingerprint: "AA", Issuer: "R3", PublicKeyAlgorithm: "RSA", NotAfter: notAfter},
+			{Host: "rotated.example.com:443", SerialNumber: "02", Fingerprint: "BB", Issuer: "R3", PublicKeyAlgorithm: "RSA", NotAfter: notAfter},
+			{Host: "migrated.example.com:443", SerialNumber: "03", Fingerprint: "CC", Issuer: "R3", PublicKeyAlgorithm: "RSA", NotAfter: notAfter},
+			{Host: "failing.example.com:443", SerialNumber: "04", Fingerprint: "DD", Issuer: "R3", PublicKeyAlgorithm: "RSA", NotAfter: notAfter},
+			{Host: "removed.example.com:443", SerialNumber: "05", Fingerprint: "EE", Issuer: "R3", PublicKeyAlgorithm: "RSA", NotAfter: notAfter},
+		},
+		Errors: []cert.ErrorInfo{
+			{Host: "recovered.example.com:443", Error: "connection refused"},
+			{Host: "down.example.com:443", Error: "connection refused"},
+		},
+	}
+
+	current := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{Host: "same.example.com:443", SerialNumber: "01", Fingerprint: "AA", Issuer: "R3", PublicKeyAlgorithm: "RSA", NotAfter: notAfter},
+			{Host: "rotated.example.com:443", SerialNumber: "12", Fingerprint: "B2", Issuer: "R3", PublicKeyAlgorithm: "RSA", NotAfter: renewed},
+			{Host: "migrated.example.com:443", SerialNumber: "13", Fingerprint: "C2", Issuer: "E5", PublicKeyAlgorithm: "ECDSA", NotAfter: renewed},
+			{Host: "recovered.example.com:443", SerialNumber: "16", Fingerprint: "FF", Issuer: "R3", PublicKeyAlgorithm: "RSA", NotAfter: renewed},
+			{Host: "added.example.com:443", SerialNumber: "17", Fingerprint: "GG", Issuer: "R3", PublicKeyAlgorithm: "RSA", NotAfter: renewed},
+		},
+		Errors: []cert.ErrorInfo{
+			{Host: "failing.example.com:443", Error: "certificate has expired"},
+			{Host: "down.example.com:443", Error: "i/o timeout"},
+		},
+	}
+
+	report := Compare(base, current)
+
+	want := []Change{
+		{Host: "added.example.com:443", Kind: ChangeAdded, New: "serial 17, expiring 2024-12-01"},
+		{Host: "failing.example.com:443", Kind: ChangeNewError, Old: "serial 04, expiring 2024-09-01", New: "certificate has expired"},
+		{Host: "migrated.example.com:443", Kind: ChangeCertificate, Old: "serial 03, expiring 2024-09-01", New: "serial 13, expiring 2024-12-01"},
+		{Host: "migrated.example.com:443", Kind: ChangeIssuer, Old: "R3", New: "E5"},
+		{Host: "migrated.example.com:443", Kind: ChangeKeyType, Old: "RSA", New: "ECDSA"},
+		{Host: "recovered.example.com:443", Kind: ChangeRecovered, Old: "connection refused", New: "serial 16, expiring 2024-12-01"},
+		{Host: "removed.example.com:443", Kind: ChangeRemoved, Old: "serial 05, expiring 2024-09-01"},
+		{Host: "rotated.example.com:443", Kind: ChangeCertificate, Old: "serial 02, expiring 2024-09-01", New: "serial 12, expiring 2024-12-01"},
+	}
+
+	if !reflect.DeepEqual(report.Changes, want) {
+		t.Errorf("Compare() =\n%+v\nwant\n%+v", report.Changes, want)
+	}
+	if !report.Changed() {
+		t.Error("Changed() = false, want true")
+	}
+
+	if Compare(base, base).Changed() {
+		t.Error("Compare() of identical results should not report changes")
+	}
+}
+
+func TestCompare_SerialFallback(t *testing.T) {
+	base := &cert.Result{Certificates: []cert.CertificateInfo{{Host: "example.com:443", SerialNumber: "01"}}}
+	current := &cert.Result{Certificates: []cert.CertificateInfo{{Host: "example.com:443", SerialNumber: "02"}}}
+
+	report := Compare(base, current)
+	if len(report.Changes) != 1 || report.Changes[0].Kind != ChangeCertificate {
+		t.Errorf("Compare() without fingerprints = %+v, want a certificate change", report.Changes)
+	}
+}
+
+func TestCompareStatus(t *testing.T) {
+	checkedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := checkedAt.AddDate(0, 0, 31)
+
+	base := &cert.Result{
+		CheckedAt: checkedAt,
+		Certificates: []cert.CertificateInfo{
+			{Host: "a.example.com:443", NotAfter: notAfter},
+			{Host: "b.example.com:443", NotAfter: notAfter.AddDate(1, 0, 0)},
+		},
+	}
+
+	// The same certificates two days later
+	current := &cert.Result{
+		CheckedAt: checkedAt.AddDate(0, 0, 2),
+		Certificates: []cert.CertificateInfo{
+			{Host: "b.example.com:443", NotAfter: notAfter.AddDate(1, 0, 0)},
+			{Host: "a.example.com:443", NotAfter: notAfter},
+			{Host: "c.example.com:443", NotAfter: notAfter},
+		},
+	}
+
+	want := []Change{{Host: "a.example.com:443", Kind: ChangeStatus, Old: "ok", New: "warning"}}
+	if got := CompareStatus(base, current, cert.DefaultThresholds()); !reflect.DeepEqual(got, want) {
+		t.Errorf("CompareStatus() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{Host: "example.com:443", SerialNumber: "01", Fingerprint: "AA", Issuer: "R3", NotAfter: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		Errors: []cert.ErrorInfo{
+			{Host: "down.example.com:443", Error: "connection refused"},
+		},
+	}
+
+	formatter := output.New()
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(dir, "result."+format)
+			if err := formatter.WriteFile(path, result, format); err != nil {
+				t.Fatalf("WriteFile() unexpected error: %v", err)
+			}
+
+			loaded, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() unexpected error: %v", err)
+			}
+
+			if Compare(result, loaded).Changed() {
+				t.Errorf("Load() = %+v, want %+v", loaded, result)
+			}
+		})
+	}
+
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(empty, []byte("\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	for _, path := range []string{empty, filepath.Join(dir, "missing.json")} {
+		if _, err := Load(path); err == nil {
+			t.Errorf("Load(%s) expected error but got none", path)
+		}
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte("{"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := Load(invalid); err == nil {
+		t.Error("Load() expected error for invalid JSON but got none")
+	}
+}
+
+func TestCompare_MixedBaseline(t *testing.T) {
+	// The baseline was saved before fingerprints were recorded
+	base := &cert.Result{Certificates: []cert.CertificateInfo{
+		{Host: "same.example.com:443", SerialNumber: "01"},
+		{Host: "rotated.example.com:443", SerialNumber: "02"},
+	}}
+	current := &cert.Result{Certificates: []cert.CertificateInfo{
+		{Host: "same.example.com:443", SerialNumber: "01", Fingerprint: "aa"},
+		{Host: "rotated.example.com:443", SerialNumber: "12", Fingerprint: "bb"},
+	}}
+
+	report := Compare(base, current)
+	if len(report.Changes) != 1 || report.Changes[0].Host != "rotated.example.com:443" || report.Changes[0].Kind != ChangeCertificate {
+		t.Errorf("Compare() with a baseline without fingerprints = %+v, want only the rotated host", report.Changes)
+	}
+}
@@ -0,0 +1,35 @@
+package diff
+
+import "github.com/guessi/ssl-certs-checker/pkg/cert"
+
+type ChangeKind string
+
+const (
+	ChangeAdded       ChangeKind = "added"
+	ChangeRemoved     ChangeKind = "removed"
+	ChangeCertificate ChangeKind = "certificate_changed"
+	ChangeIssuer      ChangeKind = "issuer_changed"
+	ChangeKeyType     ChangeKind = "key_type_changed"
+	ChangeNewError    ChangeKind = "new_error"
+	ChangeRecovered   ChangeKind = "recovered"
+	ChangeStatus      ChangeKind = "status_changed"
+)
+
+// Change is a single difference of a host between two results
+type Change struct {
+	Host string     `json:"host"`
+	Kind ChangeKind `json:"kind"`
+	Old  string     `json:"old,omitempty"`
+	New  string     `json:"new,omitempty"`
+}
+
+// Report lists the differences between two results
+type Report struct {
+	Changes []Change `json:"changes"`
+}
+
+// state is the outcome of a host in one of the compared results
+type state struct {
+	certificate *cert.CertificateVersion
+	err         string
+}
@@ -0,0 +1,402 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// fieldNames lists the fields available to filter expressions
+var fieldNames = []string{
+	"host",
+	"common_name",
+	"dns_names",
+	"issuer",
+	"serial",
+	"algorithm",
+	"tags",
+	"days_left",
+	"status",
+	"failing",
+	"error",
+	"kind",
+}
+
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!", "(", ")", "-"}
+
+// ParseExpression compiles a filter expression
+func ParseExpression(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", next.text, next.pos+1)
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Match reports whether the expression holds for the given fields
+func (e *Expression) Match(fields Fields) bool {
+	return truthy(e.root.eval(fields))
+}
+
+// tokenize splits an expression into tokens
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+
+		case c == '"' || c == '\'':
+			value, end, err := scanString(source, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: source[i:end], value: value, pos: i})
+			i = end
+
+		case c >= '0' && c <= '9' || c == '.':
+			end := i
+			for end < len(source) && (source[end] >= '0' && source[end] <= '9' || source[end] == '.') {
+				end++
+			}
+			value, err := strconv.ParseFloat(source[i:end], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", source[i:end], i+1)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[i:end], value: value, pos: i})
+			i = end
+
+		case c == '_' || unicode.IsLetter(c):
+			end := i
+			for end < len(source) && (source[end] == '_' || unicode.IsLetter(rune(source[end])) || unicode.IsDigit(rune(source[end]))) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[i:end], pos: i})
+			i = end
+
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(source[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i+1)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, text: "end of expression", pos: len(source)}), nil
+}
+
+// scanString reads a quoted string starting at start, returning its value
+// and the position following the closing quote. Only quotes and backslashes
+// are escaped, other backslashes are kept for regular expressions.
+func scanString(source string, start int) (string, int, error) {
+	quote := source[start]
+	var b strings.Builder
+
+	for i := start + 1; i < len(source); i++ {
+		switch source[i] {
+		case quote:
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 < len(source) && strings.IndexByte("\"'\\", source[i+1]) >= 0 {
+				i++
+			}
+			b.WriteByte(source[i])
+		default:
+			b.WriteByte(source[i])
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string at position %d", start+1)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token when it is one of the given operators
+func (p *parser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind == tokenOperator && slices.Contains(ops, t.text) {
+		p.pos++
+		return t.text, true
+	}
+	return "", false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept("||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "||", left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept("&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "&&", left: left, right: right}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	if _, ok := p.accept("!"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if op, ok := p.accept("=~", "!~"); ok {
+		t := p.next()
+		if t.kind != tokenString {
+			return nil, fmt.Errorf("%s expects a quoted regular expression at position %d", op, t.pos+1)
+		}
+		re, err := regexp.Compile(t.value.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression at position %d: %w", t.pos+1, err)
+		}
+		return &matchNode{operand: left, re: re, negate: op == "!~"}, nil
+	}
+
+	if op, ok := p.accept("==", "!=", "<", "<=", ">", ">="); ok {
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{op: op, left: left, right: right}, nil
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenNumber, tokenString:
+		return &literalNode{value: t.value}, nil
+
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+		if !slices.Contains(fieldNames, t.text) {
+			return nil, fmt.Errorf("unknown field %q at position %d (supported: %s)", t.text, t.pos+1, strings.Join(fieldNames, ", "))
+		}
+		return &fieldNode{name: t.text}, nil
+
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.accept(")"); !ok {
+				return nil, fmt.Errorf("missing closing parenthesis at position %d", p.peek().pos+1)
+			}
+			return inner, nil
+		case "-":
+			number := p.next()
+			if number.kind != tokenNumber {
+				return nil, fmt.Errorf("expected a number at position %d", number.pos+1)
+			}
+			return &literalNode{value: -number.value.(float64)}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos+1)
+}
+
+func (n *literalNode) eval(Fields) any {
+	return n.value
+}
+
+func (n *fieldNode) eval(fields Fields) any {
+	return fields[n.name]
+}
+
+func (n *notNode) eval(fields Fields) any {
+	return !truthy(n.operand.eval(fields))
+}
+
+func (n *matchNode) eval(fields Fields) any {
+	matched := false
+	switch v := n.operand.eval(fields).(type) {
+	case string:
+		matched = n.re.MatchString(v)
+	case []string:
+		matched = slices.ContainsFunc(v, n.re.MatchString)
+	default:
+		// Unknown values never match, also not their negation
+		return false
+	}
+	return matched != n.negate
+}
+
+func (n *binaryNode) eval(fields Fields) any {
+	switch n.op {
+	case "&&":
+		return truthy(n.left.eval(fields)) && truthy(n.right.eval(fields))
+	case "||":
+		return truthy(n.left.eval(fields)) || truthy(n.right.eval(fields))
+	}
+
+	left, right := n.left.eval(fields), n.right.eval(fields)
+
+	switch n.op {
+	case "==":
+		return equal(left, right)
+	case "!=":
+		return !equal(left, right)
+	}
+
+	cmp, ok := compare(left, right)
+	if !ok {
+		return false
+	}
+
+	switch n.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// truthy reports whether a value counts as true in a boolean context
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []string:
+		return len(v) > 0
+	default:
+		return false
+	}
+}
+
+// equal compares two values, a list equals a string it contains
+func equal(left, right any) bool {
+	if list, ok := left.([]string); ok {
+		if s, ok := right.(string); ok {
+			return slices.Contains(list, s)
+		}
+	}
+	if list, ok := right.([]string); ok {
+		if s, ok := left.(string); ok {
+			return slices.Contains(list, s)
+		}
+	}
+
+	switch l := left.(type) {
+	case nil:
+		return right == nil
+	case float64, string, bool:
+		return l == right
+	default:
+		return false
+	}
+}
+
+// compare orders two numbers or two strings
+func compare(left, right any) (int, bool) {
+	switch l := left.(type) {
+	case float64:
+		if r, ok := right.(float64); ok {
+			switch {
+			case l < r:
+				return -1, true
+			case l > r:
+				return 1, true
+			}
+			return 0, true
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			return strings.Compare(l, r), true
+		}
+	}
+	return 0, false
+}
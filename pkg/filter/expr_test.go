@@ -0,0 +1,84 @@
+package filter
+
+import (
+	"testing"
+)
+
+func TestExpression_Match(t *testing.T) {
+	fields := Fields{
+		"host":      "example.com:443",
+		"issuer":    "Let's Encrypt R3",
+		"days_left": float64(10),
+		"status":    "warning",
+		"failing":   false,
+		"tags":      []string{"production", "web"},
+		"error":     nil,
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		want       bool
+	}{
+		{name: "number comparison", expression: "days_left < 14", want: true},
+		{name: "number comparison false", expression: "days_left >= 14", want: false},
+		{name: "negative number", expression: "days_left > -3", want: true},
+		{name: "regular expression", expression: `issuer =~ "Let's Encrypt"`, want: true},
+		{name: "negated regular expression", expression: `issuer !~ "^DigiCert"`, want: true},
+		{name: "single quoted string", expression: `status == 'warning'`, want: true},
+		{name: "and", expression: `days_left < 14 && issuer =~ "Let's Encrypt"`, want: true},
+		{name: "or", expression: `failing || status == "warning"`, want: true},
+		{name: "not", expression: `!failing`, want: true},
+		{name: "parentheses", expression: `!(days_left < 14 || failing)`, want: false},
+		{name: "list contains", expression: `tags == "web"`, want: true},
+		{name: "list regular expression", expression: `tags =~ "^prod"`, want: true},
+		{name: "list does not contain", expression: `tags != "staging"`, want: true},
+		{name: "unknown value never matches", expression: `error =~ "timeout"`, want: false},
+		{name: "unknown value never matches negated", expression: `error !~ "timeout"`, want: false},
+		{name: "unknown value is null", expression: `error == null`, want: true},
+		{name: "mismatched types", expression: `host < 3`, want: false},
+		{name: "boolean literal", expression: `failing == false`, want: true},
+		{name: "escaped quote", expression: `issuer =~ "Let\'s"`, want: true},
+		{name: "escaped dot", expression: `host =~ "^example\.com:"`, want: true},
+		{name: "escaped dot does not match any character", expression: `host =~ "^example\.co\.:"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("ParseExpression(%q) unexpected error: %v", tt.expression, err)
+			}
+
+			if got := expr.Match(fields); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExpression_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{name: "empty", expression: ""},
+		{name: "unknown field", expression: "expiry < 3"},
+		{name: "unterminated string", expression: `issuer =~ "Let's`},
+		{name: "missing operand", expression: "days_left <"},
+		{name: "missing parenthesis", expression: "(days_left < 3"},
+		{name: "trailing tokens", expression: "days_left < 3 4"},
+		{name: "regular expression not quoted", expression: "issuer =~ host"},
+		{name: "invalid regular expression", expression: `issuer =~ "("`},
+		{name: "unexpected character", expression: "days_left < 3 & failing"},
+		{name: "invalid number", expression: "days_left < 1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseExpression(tt.expression); err == nil {
+				t.Errorf("ParseExpression(%q) expected error but got none", tt.expression)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package filter
+
+import "regexp"
+
+// Expression is a compiled filter expression such as
+// `days_left < 14 && issuer =~ "Let's Encrypt"`
+type Expression struct {
+	source string
+	root   node
+}
+
+// Fields holds the values of a single result the expression is evaluated
+// against, values are float64, string, bool, []string or nil when unknown
+type Fields map[string]any
+
+type node interface {
+	eval(fields Fields) any
+}
+
+type literalNode struct {
+	value any
+}
+
+type fieldNode struct {
+	name string
+}
+
+type notNode struct {
+	operand node
+}
+
+type binaryNode struct {
+	op    string
+	left  node
+	right node
+}
+
+type matchNode struct {
+	operand node
+	re      *regexp.Regexp
+	negate  bool
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value any
+	pos   int
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
@@ -0,0 +1,199 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// New creates a filter, compiling its regular expression and expression
+func New(opts Options) (*Filter, error) {
+	f := &Filter{
+		options: opts,
+		now:     time.Now,
+	}
+
+	if opts.ExpiringWithin < 0 {
+		return nil, fmt.Errorf("expiry window must not be negative")
+	}
+
+	if opts.IssuerMatch != "" {
+		re, err := regexp.Compile(opts.IssuerMatch)
+		if err != nil {
+			return nil, fmt.Errorf("invalid issuer pattern: %w", err)
+		}
+		f.issuer = re
+	}
+
+	if strings.TrimSpace(opts.Expression) != "" {
+		expr, err := ParseExpression(opts.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter expression: %w", err)
+		}
+		f.expr = expr
+	}
+
+	return f, nil
+}
+
+// ParseWindow parses a time window given in days such as "30d", or as a
+// Go duration such as "72h"
+func ParseWindow(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+
+	if days, found := strings.CutSuffix(s, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid time window: %s", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid time window: %s", s)
+	}
+
+	return d, nil
+}
+
+// Active reports whether the filter removes any results at all
+func (f *Filter) Active() bool {
+	return f.selects() || f.issuer != nil || len(f.options.Tags) > 0 || f.expr != nil
+}
+
+// Apply returns a copy of the result holding only the matching certificates
+// and errors
+func (f *Filter) Apply(result *cert.Result) *cert.Result {
+	filtered := &cert.Result{
+		Certificates: make([]cert.CertificateInfo, 0, len(result.Certificates)),
+		Errors:       make([]cert.ErrorInfo, 0, len(result.Errors)),
+		CheckedAt:    result.CheckedAt,
+		Duration:     result.Duration,
+
+		ConfigVersion: result.ConfigVersion,
+	}
+
+	for _, certInfo := range result.Certificates {
+		if f.MatchCertificate(certInfo) {
+			filtered.Certificates = append(filtered.Certificates, certInfo)
+		}
+	}
+
+	for _, errorInfo := range result.Errors {
+		if f.MatchError(errorInfo) {
+			filtered.Errors = append(filtered.Errors, errorInfo)
+		}
+	}
+
+	// Rotations are kept along with the certificates of their hosts
+	for _, rotation := range result.Rotations {
+		if slices.ContainsFunc(filtered.Certificates, func(certInfo cert.CertificateInfo) bool {
+			return certInfo.Host == rotation.Host && certInfo.ServerName == rotation.ServerName
+		}) {
+			filtered.Rotations = append(filtered.Rotations, rotation)
+		}
+	}
+
+	return filtered
+}
+
+// Match reports whether the outcome of a single host passes the filter
+func (f *Filter) Match(outcome cert.Outcome) bool {
+	if outcome.Error != nil {
+		return f.MatchError(*outcome.Error)
+	}
+	if outcome.Certificate != nil {
+		return f.MatchCertificate(*outcome.Certificate)
+	}
+	return false
+}
+
+// MatchCertificate reports whether a certificate passes the filter
+func (f *Filter) MatchCertificate(certInfo cert.CertificateInfo) bool {
+	now := f.now()
+	status, _ := f.options.Thresholds.Evaluate(certInfo, now)
+	failing := status == cert.StatusCritical
+
+	if f.selects() {
+		expiring := f.options.ExpiringWithin > 0 && certInfo.NotAfter.Before(now.Add(f.options.ExpiringWithin))
+		if !(f.options.OnlyFailing && failing) && !expiring {
+			return false
+		}
+	}
+
+	if f.issuer != nil && !f.issuer.MatchString(certInfo.Issuer) {
+		return false
+	}
+
+	if !f.matchTags(certInfo.Tags) {
+		return false
+	}
+
+	if f.expr != nil {
+		return f.expr.Match(Fields{
+			"host":        certInfo.Host,
+			"common_name": certInfo.CommonName,
+			"dns_names":   certInfo.DNSNames,
+			"issuer":      certInfo.Issuer,
+			"serial":      certInfo.SerialNumber,
+			"algorithm":   certInfo.PublicKeyAlgorithm,
+			"tags":        certInfo.Tags,
+			"days_left":   float64(certInfo.DaysLeft(now)),
+			"status":      string(status),
+			"failing":     failing,
+		})
+	}
+
+	return true
+}
+
+// MatchError reports whether a failed check passes the filter, failed
+// checks are failing but never expiring
+func (f *Filter) MatchError(errorInfo cert.ErrorInfo) bool {
+	if f.selects() && !f.options.OnlyFailing {
+		return false
+	}
+
+	if f.issuer != nil {
+		return false
+	}
+
+	if !f.matchTags(errorInfo.Tags) {
+		return false
+	}
+
+	if f.expr != nil {
+		return f.expr.Match(Fields{
+			"host":    errorInfo.Host,
+			"tags":    errorInfo.Tags,
+			"status":  string(cert.StatusError),
+			"failing": true,
+			"error":   errorInfo.Error,
+			"kind":    string(errorInfo.Kind),
+		})
+	}
+
+	return true
+}
+
+// selects reports whether results are selected by status or expiry
+func (f *Filter) selects() bool {
+	return f.options.OnlyFailing || f.options.ExpiringWithin > 0
+}
+
+// matchTags reports whether the tags contain any of the wanted tags
+func (f *Filter) matchTags(tags []string) bool {
+	if len(f.options.Tags) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(f.options.Tags, func(tag string) bool {
+		return slices.Contains(tags, tag)
+	})
+}
@@ -0,0 +1,200 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+func TestFilter_Apply(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{Host: "ok.example.com:443", Issuer: "DigiCert", NotBefore: now.AddDate(0, -1, 0), NotAfter: now.AddDate(0, 0, 90), Tags: []string{"production"}},
+			{Host: "soon.example.com:443", Issuer: "Let's Encrypt R3", NotBefore: now.AddDate(0, -1, 0), NotAfter: now.AddDate(0, 0, 20), Tags: []string{"staging"}},
+			{Host: "expired.example.com:443", Issuer: "Let's Encrypt R3", NotBefore: now.AddDate(0, -3, 0), NotAfter: now.AddDate(0, 0, -3)},
+		},
+		Errors: []cert.ErrorInfo{
+			{Host: "down.example.com:443", Error: "connection refused", Kind: cert.ErrorKindConnection, Tags: []string{"production"}},
+		},
+		CheckedAt: now,
+		Duration:  time.Second,
+	}
+
+	tests := []struct {
+		name    string
+		options Options
+		want    []string
+	}{
+		{
+			name: "no criteria",
+			want: []string{"ok.example.com:443", "soon.example.com:443", "expired.example.com:443", "down.example.com:443"},
+		},
+		{
+			name:    "only failing",
+			options: Options{OnlyFailing: true},
+			want:    []string{"expired.example.com:443", "down.example.com:443"},
+		},
+		{
+			name:    "expiring within",
+			options: Options{ExpiringWithin: 30 * 24 * time.Hour},
+			want:    []string{"soon.example.com:443", "expired.example.com:443"},
+		},
+		{
+			name:    "expiring within or failing",
+			options: Options{OnlyFailing: true, ExpiringWithin: 30 * 24 * time.Hour},
+			want:    []string{"soon.example.com:443", "expired.example.com:443", "down.example.com:443"},
+		},
+		{
+			name:    "issuer match",
+			options: Options{IssuerMatch: "^Let's Encrypt"},
+			want:    []string{"soon.example.com:443", "expired.example.com:443"},
+		},
+		{
+			name:    "tags",
+			options: Options{Tags: []string{"production", "qa"}},
+			want:    []string{"ok.example.com:443", "down.example.com:443"},
+		},
+		{
+			name:    "expression",
+			options: Options{Expression: `days_left < 60 && issuer =~ "Let's Encrypt" || kind == "connection"`},
+			want:    []string{"soon.example.com:443", "expired.example.com:443", "down.example.com:443"},
+		},
+		{
+			name:    "selection narrowed down",
+			options: Options{OnlyFailing: true, Tags: []string{"production"}},
+			want:    []string{"down.example.com:443"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			options.Thresholds = cert.DefaultThresholds()
+
+			f, err := New(options)
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			f.now = func() time.Time { return now }
+
+			filtered := f.Apply(result)
+
+			var got []string
+			for _, certInfo := range filtered.Certificates {
+				got = append(got, certInfo.Host)
+			}
+			for _, errorInfo := range filtered.Errors {
+				got = append(got, errorInfo.Host)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Apply() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Apply() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+
+			if !filtered.CheckedAt.Equal(result.CheckedAt) || filtered.Duration != result.Duration {
+				t.Error("Apply() should keep the run timing")
+			}
+		})
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	f, err := New(Options{OnlyFailing: true, Thresholds: cert.DefaultThresholds()})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	if !f.Match(cert.Outcome{Error: &cert.ErrorInfo{Host: "down.example.com:443"}}) {
+		t.Error("Match() should keep failed checks")
+	}
+	if f.Match(cert.Outcome{Certificate: &cert.CertificateInfo{NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().AddDate(1, 0, 0)}}) {
+		t.Error("Match() should drop valid certificates")
+	}
+	if f.Match(cert.Outcome{}) {
+		t.Error("Match() should drop empty outcomes")
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+	}{
+		{name: "invalid issuer pattern", options: Options{IssuerMatch: "("}},
+		{name: "invalid expression", options: Options{Expression: "days_left <"}},
+		{name: "negative window", options: Options{ExpiringWithin: -time.Hour}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.options); err == nil {
+				t.Error("New() expected error but got none")
+			}
+		})
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "30d", want: 30 * 24 * time.Hour},
+		{input: "0d", want: 0},
+		{input: "72h", want: 72 * time.Hour},
+		{input: "1h30m", want: 90 * time.Minute},
+		{input: "d", wantErr: true},
+		{input: "-3d", wantErr: true},
+		{input: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseWindow(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseWindow(%q) expected error but got none", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWindow(%q) unexpected error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseWindow(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_Apply_Rotations(t *testing.T) {
+	f, err := New(Options{Tags: []string{"production"}})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{Host: "a.example.com:443", ServerName: "a.example.com", Tags: []string{"production"}},
+			{Host: "b.example.com:443", ServerName: "b.example.com"},
+		},
+		Rotations: []cert.Rotation{
+			{Host: "a.example.com:443", ServerName: "a.example.com"},
+			{Host: "b.example.com:443", ServerName: "b.example.com"},
+		},
+	}
+
+	filtered := f.Apply(result)
+	if len(filtered.Rotations) != 1 || filtered.Rotations[0].Host != "a.example.com:443" {
+		t.Errorf("Apply() rotations = %+v, want the rotation of a.example.com:443", filtered.Rotations)
+	}
+}
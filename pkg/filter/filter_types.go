@@ -0,0 +1,27 @@
+package filter
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// Options configures which results are kept. OnlyFailing and ExpiringWithin
+// select results, a result is selected when it matches any of them. The other
+// criteria narrow down the selection, a result has to match all of them.
+type Options struct {
+	OnlyFailing    bool
+	ExpiringWithin time.Duration
+	IssuerMatch    string
+	Tags           []string
+	Expression     string
+	Thresholds     cert.Thresholds
+}
+
+type Filter struct {
+	options Options
+	issuer  *regexp.Regexp
+	expr    *Expression
+	now     func() time.Time
+}
@@ -0,0 +1,311 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+const (
+	filePrefix = "results-"
+	fileSuffix = ".jsonl"
+	dayLayout  = "2006-01-02"
+)
+
+// New creates a store in dir, creating the directory when needed. Entries
+// older than retention are pruned, a zero retention keeps them forever.
+func New(dir string, retention time.Duration) (*Store, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("history directory cannot be empty")
+	}
+
+	if retention < 0 {
+		return nil, fmt.Errorf("history retention must not be negative")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("cannot create history directory: %w", err)
+	}
+
+	return &Store{
+		dir:       dir,
+		retention: retention,
+		now:       time.Now,
+	}, nil
+}
+
+// Append records the outcome of every host of a run and prunes expired entries
+func (s *Store) Append(result *cert.Result, thresholds cert.Thresholds) error {
+	checkedAt := result.CheckedAt
+	if checkedAt.IsZero() {
+		checkedAt = s.now()
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+
+	for _, entry := range NewEntries(result, thresholds, checkedAt) {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("error marshaling history entry: %w", err)
+		}
+	}
+
+	path := filepath.Join(s.dir, filePrefix+checkedAt.UTC().Format(dayLayout)+fileSuffix)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open history file: %w", err)
+	}
+
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return fmt.Errorf("cannot write history file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("cannot write history file: %w", err)
+	}
+
+	return s.Prune()
+}
+
+// NewEntries converts the results of a run into history entries
+func NewEntries(result *cert.Result, thresholds cert.Thresholds, checkedAt time.Time) []Entry {
+	entries := make([]Entry, 0, len(result.Certificates)+len(result.Errors))
+
+	for _, certInfo := range result.Certificates {
+		status, _ := thresholds.Evaluate(certInfo, checkedAt)
+		daysLeft := certInfo.DaysLeft(checkedAt)
+
+		entries = append(entries, Entry{
+			CheckedAt:    checkedAt,
+			Host:         certInfo.Host,
+			Status:       status,
+			SerialNumber: certInfo.SerialNumber,
+			Fingerprint:  certInfo.Fingerprint,
+			Issuer:       certInfo.Issuer,
+			NotAfter:     certInfo.NotAfter,
+			DaysLeft:     &daysLeft,
+		})
+	}
+
+	for _, errorInfo := range result.Errors {
+		entries = append(entries, Entry{
+			CheckedAt: checkedAt,
+			Host:      errorInfo.Host,
+			Status:    cert.StatusError,
+			Error:     errorInfo.Error,
+		})
+	}
+
+	return entries
+}
+
+// Prune removes the daily files that only hold entries older than the retention
+func (s *Store) Prune() error {
+	if s.retention == 0 {
+		return nil
+	}
+
+	days, err := s.days()
+	if err != nil {
+		return err
+	}
+
+	cutoff := s.now().Add(-s.retention)
+	for _, day := range days {
+		// A daily file holds entries until the end of its day
+		if !day.AddDate(0, 0, 1).Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(s.path(day)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot prune history file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Entries returns the entries recorded since the given time, in chronological order
+func (s *Store) Entries(since time.Time) ([]Entry, error) {
+	days, err := s.days()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, day := range days {
+		if day.AddDate(0, 0, 1).Before(since) {
+			continue
+		}
+
+		dayEntries, err := readEntries(s.path(day))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range dayEntries {
+			if !entry.CheckedAt.Before(since) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CheckedAt.Before(entries[j].CheckedAt)
+	})
+
+	return entries, nil
+}
+
+// days lists the days the store holds a file for, oldest first
+func (s *Store) days() ([]time.Time, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read history directory: %w", err)
+	}
+
+	var days []time.Time
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+
+		day, err := time.Parse(dayLayout, strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix))
+		if err != nil {
+			continue
+		}
+		days = append(days, day)
+	}
+
+	slices.SortFunc(days, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+
+	return days, nil
+}
+
+// path returns the file holding the entries of a day
+func (s *Store) path(day time.Time) string {
+	return filepath.Join(s.dir, filePrefix+day.Format(dayLayout)+fileSuffix)
+}
+
+// readEntries reads the entries of a single file
+func readEntries(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read history file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid history entry at %s:%d: %w", filepath.Base(path), line, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read history file: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Timelines groups entries by host, sorted by host, and derives the
+// rotations, failures and recoveries between consecutive entries
+func Timelines(entries []Entry) []Timeline {
+	byHost := make(map[string][]Entry)
+	for _, entry := range entries {
+		byHost[entry.Host] = append(byHost[entry.Host], entry)
+	}
+
+	hosts := make([]string, 0, len(byHost))
+	for host := range byHost {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	timelines := make([]Timeline, 0, len(hosts))
+	for _, host := range hosts {
+		hostEntries := byHost[host]
+		sort.SliceStable(hostEntries, func(i, j int) bool {
+			return hostEntries[i].CheckedAt.Before(hostEntries[j].CheckedAt)
+		})
+
+		timelines = append(timelines, Timeline{
+			Host:    host,
+			Entries: hostEntries,
+			Events:  events(hostEntries),
+		})
+	}
+
+	return timelines
+}
+
+// events derives the notable changes of a chronologically sorted list of entries
+func events(entries []Entry) []Event {
+	events := make([]Event, 0)
+	var last *Entry
+
+	for i := range entries {
+		entry := &entries[i]
+
+		switch {
+		case i == 0:
+			events = append(events, Event{At: entry.CheckedAt, Kind: EventFirstSeen, Description: describe(*entry)})
+
+		case entry.Status == cert.StatusError && last.Status != cert.StatusError:
+			events = append(events, Event{At: entry.CheckedAt, Kind: EventFailing, Description: entry.Error})
+
+		case entry.Status != cert.StatusError && last.Status == cert.StatusError:
+			events = append(events, Event{At: entry.CheckedAt, Kind: EventRecovered, Description: describe(*entry)})
+		}
+
+		if entry.Status != cert.StatusError {
+			if last != nil && last.Status != cert.StatusError && last.Fingerprint != "" && entry.Fingerprint != "" && last.Fingerprint != entry.Fingerprint {
+				events = append(events, Event{
+					At:          entry.CheckedAt,
+					Kind:        EventRotated,
+					Description: fmt.Sprintf("serial %s -> %s, expiring %s", last.SerialNumber, entry.SerialNumber, entry.NotAfter.UTC().Format(time.DateOnly)),
+				})
+			} else if last != nil && last.Status != cert.StatusError && last.Status != entry.Status {
+				events = append(events, Event{
+					At:          entry.CheckedAt,
+					Kind:        EventStatusChanged,
+					Description: fmt.Sprintf("%s -> %s", last.Status, entry.Status),
+				})
+			}
+		}
+
+		last = entry
+	}
+
+	return events
+}
+
+// describe summarizes the certificate of an entry
+func describe(entry Entry) string {
+	if entry.Status == cert.StatusError {
+		return entry.Error
+	}
+	return fmt.Sprintf("serial %s, %s, expiring %s", entry.SerialNumber, entry.Status, entry.NotAfter.UTC().Format(time.DateOnly))
+}
@@ -0,0 +1,171 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+func TestNew(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "history")
+
+	if _, err := New(dir, 0); err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Error("New() should create the history directory")
+	}
+
+	if _, err := New("", 0); err == nil {
+		t.Error("New() should fail without a directory")
+	}
+	if _, err := New(dir, -time.Hour); err == nil {
+		t.Error("New() should fail with a negative retention")
+	}
+}
+
+func TestStore_AppendAndEntries(t *testing.T) {
+	store, err := New(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	day1 := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	runs := []*cert.Result{
+		{
+			CheckedAt: day1,
+			Certificates: []cert.CertificateInfo{
+				{Host: "example.com:443", SerialNumber: "0A", Fingerprint: "AA", Issuer: "R3", NotBefore: day1.AddDate(0, -2, 0), NotAfter: day1.AddDate(0, 0, 10)},
+			},
+			Errors: []cert.ErrorInfo{
+				{Host: "down.example.com:443", Error: "connection refused"},
+			},
+		},
+		{
+			CheckedAt: day2,
+			Certificates: []cert.CertificateInfo{
+				{Host: "example.com:443", SerialNumber: "0B", Fingerprint: "BB", Issuer: "R3", NotBefore: day2, NotAfter: day2.AddDate(0, 0, 90)},
+				{Host: "down.example.com:443", SerialNumber: "0C", Fingerprint: "CC", Issuer: "R3", NotBefore: day2, NotAfter: day2.AddDate(0, 0, 90)},
+			},
+		},
+	}
+
+	for _, run := range runs {
+		if err := store.Append(run, cert.DefaultThresholds()); err != nil {
+			t.Fatalf("Append() unexpected error: %v", err)
+		}
+	}
+
+	files, _ := os.ReadDir(store.dir)
+	if len(files) != 2 {
+		t.Errorf("Append() should write one file per day, got %d", len(files))
+	}
+
+	entries, err := store.Entries(time.Time{})
+	if err != nil {
+		t.Fatalf("Entries() unexpected error: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("Entries() returned %d entries, want 4", len(entries))
+	}
+	if entries[0].Status != cert.StatusWarning || entries[0].DaysLeft == nil || *entries[0].DaysLeft != 10 {
+		t.Errorf("Entries() first entry = %+v", entries[0])
+	}
+	if entries[1].Status != cert.StatusError || entries[1].DaysLeft != nil || !entries[1].NotAfter.IsZero() {
+		t.Errorf("Entries() error entry = %+v", entries[1])
+	}
+
+	entries, err = store.Entries(day2)
+	if err != nil {
+		t.Fatalf("Entries() unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Entries() since the second run returned %d entries, want 2", len(entries))
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	dir := t.TempDir()
+	store, err := New(dir, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	for _, name := range []string{"results-2024-06-01.jsonl", "results-2024-06-22.jsonl", "results-2024-06-23.jsonl", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	if err := store.Append(&cert.Result{CheckedAt: now}, cert.DefaultThresholds()); err != nil {
+		t.Fatalf("Append() unexpected error: %v", err)
+	}
+
+	var names []string
+	files, _ := os.ReadDir(dir)
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+
+	want := []string{"notes.txt", "results-2024-06-23.jsonl", "results-2024-06-30.jsonl"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Prune() left %v, want %v", names, want)
+	}
+}
+
+func TestStore_Entries_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	store, err := New(dir, 0)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "results-2024-06-01.jsonl"), []byte("{\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if _, err := store.Entries(time.Time{}); err == nil {
+		t.Error("Entries() should fail on invalid entries")
+	}
+}
+
+func TestTimelines(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	days := func(n int) *int { return &n }
+
+	entries := []Entry{
+		{CheckedAt: start, Host: "b.example.com:443", Status: cert.StatusOK, SerialNumber: "0A", Fingerprint: "AA", NotAfter: start.AddDate(0, 0, 40), DaysLeft: days(40)},
+		{CheckedAt: start, Host: "a.example.com:443", Status: cert.StatusError, Error: "connection refused"},
+		{CheckedAt: start.AddDate(0, 0, 15), Host: "b.example.com:443", Status: cert.StatusWarning, SerialNumber: "0A", Fingerprint: "AA", NotAfter: start.AddDate(0, 0, 40), DaysLeft: days(25)},
+		{CheckedAt: start.AddDate(0, 0, 16), Host: "b.example.com:443", Status: cert.StatusOK, SerialNumber: "0B", Fingerprint: "BB", NotAfter: start.AddDate(0, 0, 106), DaysLeft: days(90)},
+		{CheckedAt: start.AddDate(0, 0, 17), Host: "b.example.com:443", Status: cert.StatusError, Error: "timeout"},
+		{CheckedAt: start.AddDate(0, 0, 18), Host: "b.example.com:443", Status: cert.StatusOK, SerialNumber: "0B", Fingerprint: "BB", NotAfter: start.AddDate(0, 0, 106), DaysLeft: days(88)},
+	}
+
+	timelines := Timelines(entries)
+	if len(timelines) != 2 || timelines[0].Host != "a.example.com:443" || timelines[1].Host != "b.example.com:443" {
+		t.Fatalf("Timelines() = %+v, want one timeline per host sorted by host", timelines)
+	}
+
+	var kinds []EventKind
+	for _, event := range timelines[1].Events {
+		kinds = append(kinds, event.Kind)
+	}
+
+	want := []EventKind{EventFirstSeen, EventStatusChanged, EventRotated, EventFailing, EventRecovered}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("Timelines() events = %v, want %v", kinds, want)
+	}
+	if timelines[1].Events[2].Description != "serial 0A -> 0B, expiring 2024-09-15" {
+		t.Errorf("Timelines() rotation = %q", timelines[1].Events[2].Description)
+	}
+}
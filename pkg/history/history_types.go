@@ -0,0 +1,52 @@
+package history
+
+import (
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// Store is an append-only history of check results, kept as one JSON Lines
+// file per day in a directory
+type Store struct {
+	dir       string
+	retention time.Duration
+	now       func() time.Time
+}
+
+// Entry is the outcome of checking a single host at a point in time
+type Entry struct {
+	CheckedAt    time.Time   `json:"checked_at"`
+	Host         string      `json:"host"`
+	Status       cert.Status `json:"status"`
+	SerialNumber string      `json:"serial_number,omitempty"`
+	Fingerprint  string      `json:"fingerprint_sha256,omitempty"`
+	Issuer       string      `json:"issuer,omitempty"`
+	NotAfter     time.Time   `json:"not_after,omitzero"`
+	DaysLeft     *int        `json:"days_left,omitempty"`
+	Error        string      `json:"error,omitempty"`
+}
+
+type EventKind string
+
+const (
+	EventFirstSeen     EventKind = "first_seen"
+	EventRotated       EventKind = "rotated"
+	EventStatusChanged EventKind = "status_changed"
+	EventFailing       EventKind = "failing"
+	EventRecovered     EventKind = "recovered"
+)
+
+// Event is a notable change between two consecutive entries of a host
+type Event struct {
+	At          time.Time `json:"at"`
+	Kind        EventKind `json:"kind"`
+	Description string    `json:"description"`
+}
+
+// Timeline holds the entries of a single host in chronological order
+type Timeline struct {
+	Host    string  `json:"host"`
+	Entries []Entry `json:"entries"`
+	Events  []Event `json:"events"`
+}
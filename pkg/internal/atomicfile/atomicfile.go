@@ -0,0 +1,52 @@
+// Package atomicfile replaces files so readers never observe them partially
+// written
+package atomicfile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Write writes to a temporary file next to path and renames it into place
+// once synced to disk, with 0644 permissions
+func Write(path string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot sync temporary file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot close temporary file: %w", err)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("cannot set file permissions: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("cannot rename temporary file: %w", err)
+	}
+
+	return nil
+}
+
+// WriteFile atomically replaces the content of path with data
+func WriteFile(path string, data []byte) error {
+	return Write(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
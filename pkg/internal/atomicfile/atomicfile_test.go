@@ -0,0 +1,49 @@
+package atomicfile
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	// A failing write keeps the previous content
+	err := Write(path, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return errors.New("write failed")
+	})
+	if err == nil {
+		t.Fatal("Write() expected error but got none")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("Write() failure left %q, want the previous content", data)
+	}
+
+	if err := WriteFile(path, []byte("new")); err != nil {
+		t.Fatalf("WriteFile() unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("WriteFile() content = %q, want %q", data, "new")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("WriteFile() permissions = %v, want 0644", info.Mode().Perm())
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Write() left unexpected files behind: %v", entries)
+	}
+}
@@ -0,0 +1,356 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/filter"
+	"github.com/guessi/ssl-certs-checker/pkg/output"
+	"go.yaml.in/yaml/v3"
+)
+
+const defaultSMTPTimeout = 30 * time.Second
+
+// defaultSMTPPorts are the submission ports of the connection security modes
+var defaultSMTPPorts = map[string]int{
+	SMTPStartTLS: 587,
+	SMTPTLS:      465,
+	SMTPNone:     25,
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9@._-]+`)
+
+// NewMailer creates a mailer rendering the report with the given formatter
+func NewMailer(cfg EmailConfig, formatter *output.Formatter) (*Mailer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Mailer{
+		config:    cfg,
+		formatter: formatter,
+		tlsConfig: &tls.Config{ServerName: cfg.SMTP.Host},
+		now:       time.Now,
+	}, nil
+}
+
+// UnmarshalYAML accepts a recipient given as a plain address
+func (r *Recipient) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		r.Address = value.Value
+		return nil
+	}
+
+	type plain Recipient
+	return value.Decode((*plain)(r))
+}
+
+// Validate checks the email configuration
+func (c EmailConfig) Validate() error {
+	if c.SMTP.Host == "" && c.DryRun == "" {
+		return fmt.Errorf("smtp host is required")
+	}
+
+	if c.SMTP.Security != "" {
+		if _, ok := defaultSMTPPorts[c.SMTP.Security]; !ok {
+			return fmt.Errorf("invalid smtp security: %s (supported: starttls, tls, none)", c.SMTP.Security)
+		}
+	}
+
+	if c.SMTP.Port < 0 || c.SMTP.Port > 65535 {
+		return fmt.Errorf("invalid smtp port: %d", c.SMTP.Port)
+	}
+
+	if (c.SMTP.UsernameEnv == "") != (c.SMTP.PasswordEnv == "") {
+		return fmt.Errorf("username_env and password_env must be set together")
+	}
+
+	if (c.SMTP.Username == "") != (c.SMTP.Password == "") {
+		return fmt.Errorf("username and password must be set together")
+	}
+
+	if c.SMTP.Username != "" && c.SMTP.UsernameEnv != "" {
+		return fmt.Errorf("username and username_env cannot be used together")
+	}
+
+	if c.SMTP.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+
+	if _, err := mail.ParseAddress(c.From); err != nil {
+		return fmt.Errorf("invalid from address %q: %w", c.From, err)
+	}
+
+	if len(c.Recipients) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+	for _, recipient := range c.Recipients {
+		if _, err := mail.ParseAddress(recipient.Address); err != nil {
+			return fmt.Errorf("invalid recipient address %q: %w", recipient.Address, err)
+		}
+	}
+
+	if c.Format != "" && c.Format != EmailHTML && c.Format != EmailText {
+		return fmt.Errorf("invalid email format: %s (supported: html, text)", c.Format)
+	}
+
+	return nil
+}
+
+// NotifierName returns the configured name, defaulting to "email"
+func (c EmailConfig) NotifierName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return "email"
+}
+
+// Send mails every recipient the report of its hosts, recipients without
+// any matching host are skipped
+func (m *Mailer) Send(ctx context.Context, result *cert.Result) error {
+	for _, recipient := range m.config.Recipients {
+		recipientFilter, err := filter.New(filter.Options{Tags: recipient.Tags})
+		if err != nil {
+			return err
+		}
+
+		report := recipientFilter.Apply(result)
+		if len(report.Certificates) == 0 && len(report.Errors) == 0 {
+			continue
+		}
+
+		message, err := m.message(recipient.Address, report)
+		if err != nil {
+			return fmt.Errorf("cannot build message for %s: %w", recipient.Address, err)
+		}
+
+		if m.config.DryRun != "" {
+			if err := m.writeMessage(recipient.Address, message); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := m.deliver(ctx, recipient.Address, message); err != nil {
+			return fmt.Errorf("cannot send report to %s: %w", recipient.Address, err)
+		}
+	}
+
+	return nil
+}
+
+// message renders the MIME message of a report
+func (m *Mailer) message(to string, report *cert.Result) ([]byte, error) {
+	var text bytes.Buffer
+	if err := m.formatter.FormatTo(&text, report, "table"); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	now := m.now()
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(&buf, "From: %s\r\n", m.config.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.subject(report)))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%d.%s@ssl-certs-checker>\r\n", now.UnixNano(), hex.EncodeToString(id))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+
+	if m.config.Format == EmailText {
+		fmt.Fprintf(&buf, "Content-Type: text/plain; charset=utf-8\r\n")
+		fmt.Fprintf(&buf, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, text.Bytes()); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	var html bytes.Buffer
+	if err := m.formatter.FormatHTML(&html, report); err != nil {
+		return nil, err
+	}
+
+	// The plain text part is shown by clients not displaying HTML
+	parts := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+
+	for _, part := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// subject returns the configured subject, by default the summary counts
+func (m *Mailer) subject(report *cert.Result) string {
+	if m.config.Subject != "" {
+		return m.config.Subject
+	}
+
+	summary := m.formatter.Summarize(report)
+	return fmt.Sprintf("SSL certificate report: %d ok, %d warning, %d critical, %d error(s)",
+		summary.OK, summary.Warning, summary.Critical, summary.Errors)
+}
+
+// writeMessage stores a message in the dry run directory
+func (m *Mailer) writeMessage(to string, message []byte) error {
+	if err := os.MkdirAll(m.config.DryRun, 0755); err != nil {
+		return fmt.Errorf("cannot create dry run directory: %w", err)
+	}
+
+	name := unsafeFileChars.ReplaceAllString(m.config.NotifierName()+"-"+to, "_") + ".eml"
+	if err := os.WriteFile(filepath.Join(m.config.DryRun, name), message, 0600); err != nil {
+		return fmt.Errorf("cannot write message: %w", err)
+	}
+
+	return nil
+}
+
+// deliver sends a message over SMTP
+func (m *Mailer) deliver(ctx context.Context, to string, message []byte) error {
+	smtpConfig := m.config.SMTP
+
+	security := smtpConfig.Security
+	if security == "" {
+		security = SMTPStartTLS
+	}
+
+	port := smtpConfig.Port
+	if port == 0 {
+		port = defaultSMTPPorts[security]
+	}
+
+	timeout := smtpConfig.Timeout
+	if timeout == 0 {
+		timeout = defaultSMTPTimeout
+	}
+
+	var auth smtp.Auth
+	if smtpConfig.Username != "" {
+		auth = smtp.PlainAuth("", smtpConfig.Username, smtpConfig.Password, smtpConfig.Host)
+	} else if smtpConfig.UsernameEnv != "" {
+		username, password := os.Getenv(smtpConfig.UsernameEnv), os.Getenv(smtpConfig.PasswordEnv)
+		if username == "" || password == "" {
+			return fmt.Errorf("credentials not set in %s and %s", smtpConfig.UsernameEnv, smtpConfig.PasswordEnv)
+		}
+		auth = smtp.PlainAuth("", username, password, smtpConfig.Host)
+	}
+
+	address := net.JoinHostPort(smtpConfig.Host, strconv.Itoa(port))
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var conn net.Conn
+	var err error
+	if security == SMTPTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: m.tlsConfig}).DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	client, err := smtp.NewClient(conn, smtpConfig.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if security == SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("server does not support STARTTLS")
+		}
+		if err := client.StartTLS(m.tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	// The envelope takes the bare addresses without display names
+	from, err := mail.ParseAddress(m.config.From)
+	if err != nil {
+		return err
+	}
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(recipient.Address); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// writeQuotedPrintable writes data with the quoted-printable encoding
+func writeQuotedPrintable(w io.Writer, data []byte) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write(data); err != nil {
+		return err
+	}
+	return qp.Close()
+}
@@ -0,0 +1,454 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http/httptest"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/output"
+	"go.yaml.in/yaml/v3"
+)
+
+// smtpServer is a fake SMTP server recording the messages it receives
+type smtpServer struct {
+	listener net.Listener
+	tls      *tls.Config
+	startTLS bool
+
+	mutex    sync.Mutex
+	received smtpLog
+}
+
+// smtpLog lists what a fake SMTP server received
+type smtpLog struct {
+	auth     []string
+	from     []string
+	to       []string
+	messages []string
+	secure   []bool
+}
+
+// testTLSConfig returns a server certificate for 127.0.0.1 along with a
+// client configuration trusting it
+func testTLSConfig(t *testing.T) (*tls.Config, *tls.Config) {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(nil)
+	server.StartTLS()
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	return &tls.Config{Certificates: server.TLS.Certificates}, &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"}
+}
+
+func newSMTPServer(t *testing.T, serverTLS *tls.Config, implicitTLS, startTLS bool) *smtpServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	if implicitTLS {
+		listener = tls.NewListener(listener, serverTLS)
+	}
+
+	s := &smtpServer{listener: listener, tls: serverTLS, startTLS: startTLS}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn, implicitTLS)
+		}
+	}()
+
+	return s
+}
+
+// log returns a copy of what the server received so far
+func (s *smtpServer) log() smtpLog {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.received
+}
+
+func (s *smtpServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpServer) handle(conn net.Conn, secure bool) {
+	defer func() { conn.Close() }()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 localhost ESMTP")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch command {
+		case "EHLO":
+			reply("250-localhost")
+			if s.startTLS && !secure {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			reply("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, reader, secure = tlsConn, bufio.NewReader(tlsConn), true
+		case "AUTH":
+			credentials, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, "AUTH PLAIN "))
+			s.mutex.Lock()
+			s.received.auth = append(s.received.auth, string(credentials))
+			s.mutex.Unlock()
+			reply("235 authenticated")
+		case "MAIL":
+			s.mutex.Lock()
+			s.received.from = append(s.received.from, line)
+			s.mutex.Unlock()
+			reply("250 ok")
+		case "RCPT":
+			s.mutex.Lock()
+			s.received.to = append(s.received.to, line)
+			s.mutex.Unlock()
+			reply("250 ok")
+		case "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			s.mutex.Lock()
+			s.received.messages = append(s.received.messages, data.String())
+			s.received.secure = append(s.received.secure, secure)
+			s.mutex.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+// decodeMessage returns the headers and the decoded parts of a message by
+// content type
+func decodeMessage(t *testing.T, data string) (mail.Header, map[string]string) {
+	t.Helper()
+
+	message, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("invalid message: %v\n%s", err, data)
+	}
+
+	parts := make(map[string]string)
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("invalid content type: %v", err)
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		body, _ := io.ReadAll(quotedprintable.NewReader(message.Body))
+		parts[mediaType] = string(body)
+		return message.Header, parts
+	}
+
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid part: %v", err)
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		// multipart decodes quoted-printable parts itself
+		body, _ := io.ReadAll(part)
+		parts[partType] = string(body)
+	}
+
+	return message.Header, parts
+}
+
+func testReport(now time.Time) *cert.Result {
+	return &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{Host: "www.example.com:443", CommonName: "www.example.com", NotBefore: now.AddDate(0, 0, -10), NotAfter: now.AddDate(0, 0, 20), Tags: []string{"web"}},
+			{Host: "api.example.com:443", CommonName: "api.example.com", NotBefore: now.AddDate(0, 0, -10), NotAfter: now.AddDate(0, 0, 90), Tags: []string{"api"}},
+		},
+		Errors: []cert.ErrorInfo{
+			{Host: "db.example.com:443", Error: "connection refused", Tags: []string{"db"}},
+		},
+	}
+}
+
+func newTestMailer(t *testing.T, cfg EmailConfig, clientTLS *tls.Config) *Mailer {
+	t.Helper()
+
+	mailer, err := NewMailer(cfg, output.NewWithOptions(output.Options{Thresholds: cert.DefaultThresholds()}))
+	if err != nil {
+		t.Fatalf("NewMailer() unexpected error: %v", err)
+	}
+	if clientTLS != nil {
+		mailer.tlsConfig = clientTLS
+	}
+
+	return mailer
+}
+
+func TestMailer_Send_StartTLS(t *testing.T) {
+	serverTLS, clientTLS := testTLSConfig(t)
+	server := newSMTPServer(t, serverTLS, false, true)
+
+	t.Setenv("TEST_SMTP_USERNAME", "reporter")
+	t.Setenv("TEST_SMTP_PASSWORD", "s3cret")
+
+	mailer := newTestMailer(t, EmailConfig{
+		SMTP: SMTPConfig{
+			Host:        "127.0.0.1",
+			Port:        server.port(),
+			UsernameEnv: "TEST_SMTP_USERNAME",
+			PasswordEnv: "TEST_SMTP_PASSWORD",
+		},
+		From:       "Certificate Reports <certs@example.com>",
+		Recipients: []Recipient{{Address: "ops@example.com"}},
+	}, clientTLS)
+
+	if err := mailer.Send(context.Background(), testReport(time.Now())); err != nil {
+		t.Fatalf("Send() unexpected error: %v", err)
+	}
+
+	received := server.log()
+	if len(received.messages) != 1 || !received.secure[0] {
+		t.Fatalf("server got %d message(s), secure %v", len(received.messages), received.secure)
+	}
+	if received.auth[0] != "\x00reporter\x00s3cret" {
+		t.Errorf("server got credentials %q", received.auth[0])
+	}
+	if received.from[0] != "MAIL FROM:<certs@example.com>" || received.to[0] != "RCPT TO:<ops@example.com>" {
+		t.Errorf("server got envelope %q %q", received.from[0], received.to[0])
+	}
+
+	header, parts := decodeMessage(t, received.messages[0])
+	subject, _ := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	if subject != "SSL certificate report: 1 ok, 1 warning, 0 critical, 1 error(s)" {
+		t.Errorf("Subject = %q", subject)
+	}
+	if !strings.Contains(parts["text/plain"], "www.example.com:443") || !strings.Contains(parts["text/plain"], "db.example.com:443: connection refused") {
+		t.Errorf("text part:\n%s", parts["text/plain"])
+	}
+	if !strings.Contains(parts["text/html"], "<td>api.example.com:443</td>") {
+		t.Errorf("html part:\n%s", parts["text/html"])
+	}
+}
+
+func TestMailer_Send_ImplicitTLS(t *testing.T) {
+	serverTLS, clientTLS := testTLSConfig(t)
+	server := newSMTPServer(t, serverTLS, true, false)
+
+	mailer := newTestMailer(t, EmailConfig{
+		SMTP:    SMTPConfig{Host: "127.0.0.1", Port: server.port(), Security: SMTPTLS, Username: "reporter", Password: "s3cret"},
+		From:    "certs@example.com",
+		Subject: "Weekly certificate report",
+		Format:  EmailText,
+		Recipients: []Recipient{
+			{Address: "web@example.com", Tags: []string{"web"}},
+			{Address: "dba@example.com", Tags: []string{"db"}},
+			{Address: "nobody@example.com", Tags: []string{"mail"}},
+		},
+	}, clientTLS)
+
+	if err := mailer.Send(context.Background(), testReport(time.Now())); err != nil {
+		t.Fatalf("Send() unexpected error: %v", err)
+	}
+
+	// Recipients without matching hosts get no report
+	received := server.log()
+	if len(received.messages) != 2 {
+		t.Fatalf("server got %d message(s), want 2", len(received.messages))
+	}
+	if len(received.auth) == 0 || received.auth[0] != "\x00reporter\x00s3cret" {
+		t.Errorf("server got credentials %q", received.auth)
+	}
+
+	for i, want := range []string{"www.example.com:443", "db.example.com:443"} {
+		header, parts := decodeMessage(t, received.messages[i])
+		if header.Get("Subject") != "Weekly certificate report" {
+			t.Errorf("Subject = %q", header.Get("Subject"))
+		}
+
+		text, ok := parts["text/plain"]
+		if !ok || len(parts) != 1 {
+			t.Fatalf("text report parts = %v", parts)
+		}
+		if !strings.Contains(text, want) || strings.Contains(text, "api.example.com") {
+			t.Errorf("report for %s:\n%s", received.to[i], text)
+		}
+	}
+}
+
+func TestMailer_Send_Errors(t *testing.T) {
+	serverTLS, _ := testTLSConfig(t)
+	server := newSMTPServer(t, serverTLS, false, false)
+
+	tests := []struct {
+		name    string
+		smtp    SMTPConfig
+		wantErr string
+	}{
+		{
+			name:    "no STARTTLS",
+			smtp:    SMTPConfig{Host: "127.0.0.1", Port: server.port()},
+			wantErr: "does not support STARTTLS",
+		},
+		{
+			name:    "missing credentials",
+			smtp:    SMTPConfig{Host: "127.0.0.1", Port: server.port(), Security: SMTPNone, UsernameEnv: "TEST_SMTP_UNSET_USERNAME", PasswordEnv: "TEST_SMTP_UNSET_PASSWORD"},
+			wantErr: "credentials not set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mailer := newTestMailer(t, EmailConfig{
+				SMTP:       tt.smtp,
+				From:       "certs@example.com",
+				Recipients: []Recipient{{Address: "ops@example.com"}},
+			}, nil)
+
+			err := mailer.Send(context.Background(), testReport(time.Now()))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Send() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMailer_Send_DryRun(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+
+	mailer := newTestMailer(t, EmailConfig{
+		Name:       "weekly",
+		From:       "certs@example.com",
+		Recipients: []Recipient{{Address: "Management <management@example.com>"}},
+		DryRun:     dir,
+	}, nil)
+
+	if err := mailer.Send(context.Background(), testReport(time.Now())); err != nil {
+		t.Fatalf("Send() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "weekly-Management_management@example.com_.eml"))
+	if err != nil {
+		entries, _ := os.ReadDir(dir)
+		t.Fatalf("Send() did not write the message: %v (found %v)", err, entries)
+	}
+
+	header, parts := decodeMessage(t, string(data))
+	if header.Get("To") != "Management <management@example.com>" || header.Get("Message-Id") == "" {
+		t.Errorf("message headers = %v", header)
+	}
+	if !strings.Contains(parts["text/html"], "db.example.com:443") {
+		t.Errorf("html part:\n%s", parts["text/html"])
+	}
+}
+
+func TestEmailConfig_Validate(t *testing.T) {
+	valid := EmailConfig{
+		SMTP:       SMTPConfig{Host: "smtp.example.com"},
+		From:       "certs@example.com",
+		Recipients: []Recipient{{Address: "ops@example.com"}},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(c *EmailConfig)
+		wantErr string
+	}{
+		{name: "valid", modify: func(c *EmailConfig) {}},
+		{name: "dry run without host", modify: func(c *EmailConfig) { c.SMTP.Host, c.DryRun = "", "/tmp/outbox" }},
+		{name: "missing host", modify: func(c *EmailConfig) { c.SMTP.Host = "" }, wantErr: "smtp host is required"},
+		{name: "invalid security", modify: func(c *EmailConfig) { c.SMTP.Security = "ssl" }, wantErr: "invalid smtp security"},
+		{name: "invalid port", modify: func(c *EmailConfig) { c.SMTP.Port = 70000 }, wantErr: "invalid smtp port"},
+		{name: "username without password", modify: func(c *EmailConfig) { c.SMTP.UsernameEnv = "SMTP_USERNAME" }, wantErr: "must be set together"},
+		{name: "password without username", modify: func(c *EmailConfig) { c.SMTP.Password = "s3cret" }, wantErr: "must be set together"},
+		{name: "username and username_env", modify: func(c *EmailConfig) {
+			c.SMTP.Username, c.SMTP.Password, c.SMTP.UsernameEnv, c.SMTP.PasswordEnv = "reporter", "s3cret", "SMTP_USERNAME", "SMTP_PASSWORD"
+		}, wantErr: "cannot be used together"},
+		{name: "invalid from", modify: func(c *EmailConfig) { c.From = "certs" }, wantErr: "invalid from address"},
+		{name: "no recipients", modify: func(c *EmailConfig) { c.Recipients = nil }, wantErr: "at least one recipient"},
+		{name: "invalid recipient", modify: func(c *EmailConfig) { c.Recipients = []Recipient{{Address: "ops"}} }, wantErr: "invalid recipient address"},
+		{name: "invalid format", modify: func(c *EmailConfig) { c.Format = "pdf" }, wantErr: "invalid email format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			cfg.Recipients = append([]Recipient(nil), valid.Recipients...)
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRecipient_UnmarshalYAML(t *testing.T) {
+	var cfg EmailConfig
+	content := "recipients:\n  - ops@example.com\n  - address: web@example.com\n    tags: [web]\n"
+	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+
+	want := []Recipient{{Address: "ops@example.com"}, {Address: "web@example.com", Tags: []string{"web"}}}
+	if len(cfg.Recipients) != 2 || cfg.Recipients[0].Address != want[0].Address || cfg.Recipients[1].Tags[0] != "web" {
+		t.Errorf("recipients = %+v, want %+v", cfg.Recipients, want)
+	}
+}
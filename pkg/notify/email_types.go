@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/output"
+)
+
+// SMTP connection security modes
+const (
+	SMTPStartTLS = "starttls"
+	SMTPTLS      = "tls"
+	SMTPNone     = "none"
+)
+
+// Email report formats
+const (
+	EmailHTML = "html"
+	EmailText = "text"
+)
+
+// EmailConfig configures an email report
+type EmailConfig struct {
+	Name       string      `yaml:"name"`
+	SMTP       SMTPConfig  `yaml:"smtp"`
+	From       string      `yaml:"from"`
+	Recipients []Recipient `yaml:"recipients"`
+	Subject    string      `yaml:"subject"`
+	Format     string      `yaml:"format"`
+	// DryRun is a directory the messages are written to instead of being sent
+	DryRun string `yaml:"dry_run"`
+}
+
+// SMTPConfig configures the connection to the mail server, the credentials
+// are read from the named environment variables
+type SMTPConfig struct {
+	Host        string        `yaml:"host"`
+	Port        int           `yaml:"port"`
+	Security    string        `yaml:"security"`
+	UsernameEnv string        `yaml:"username_env"`
+	PasswordEnv string        `yaml:"password_env"`
+	Timeout     time.Duration `yaml:"timeout"`
+
+	// Username and Password take the credentials directly, usually given
+	// as secret references resolved when the config file is loaded
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// Recipient receives the report of the hosts with any of its tags, or of
+// every host when it has none
+type Recipient struct {
+	Address string   `yaml:"address"`
+	Tags    []string `yaml:"tags"`
+}
+
+// Mailer sends the report of a result by email
+type Mailer struct {
+	config    EmailConfig
+	formatter *output.Formatter
+	tlsConfig *tls.Config
+	now       func() time.Time
+}
@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+const (
+	DefaultEventsURL      = "https://events.pagerduty.com/v2/enqueue"
+	defaultDedupKeyPrefix = "ssl-certs-checker:"
+)
+
+// NewEvents creates an events notifier
+func NewEvents(cfg EventsConfig) (*Events, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	e := &Events{config: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+	if e.config.URL == "" {
+		e.config.URL = DefaultEventsURL
+	}
+	if e.config.DedupKey == "" {
+		e.config.DedupKey = defaultDedupKeyPrefix
+	}
+	if e.client.Timeout == 0 {
+		e.client.Timeout = defaultWebhookTimeout
+	}
+
+	return e, nil
+}
+
+// Validate checks the events configuration
+func (c EventsConfig) Validate() error {
+	if c.RoutingKey == "" {
+		return fmt.Errorf("routing_key is required")
+	}
+
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid events url: %q", c.URL)
+		}
+	}
+
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+
+	return nil
+}
+
+// NotifierName returns the configured name, defaulting to "events"
+func (c EventsConfig) NotifierName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return "events"
+}
+
+// Name returns the name of the notifier
+func (e *Events) Name() string {
+	return e.config.NotifierName()
+}
+
+// Wants reports whether an alert opens or closes an incident: hosts turning
+// critical or failing trigger one, hosts back to OK from critical or failing
+// resolve it. Warnings never open an incident, so they have none to resolve.
+func (e *Events) Wants(alert Alert) bool {
+	if !(Policy{Tags: e.config.Tags}).matchesTags(alert) {
+		return false
+	}
+
+	switch alert.Status {
+	case cert.StatusCritical, cert.StatusError:
+		return true
+	case cert.StatusOK:
+		return alert.Previous == cert.StatusCritical || alert.Previous == cert.StatusError
+	}
+
+	return false
+}
+
+// Notify sends an event per alert. Events are idempotent thanks to the
+// dedup key, so retrying a partially delivered batch is harmless.
+func (e *Events) Notify(ctx context.Context, alerts []Alert) error {
+	for _, alert := range alerts {
+		if err := e.send(ctx, e.event(alert)); err != nil {
+			return fmt.Errorf("%s: %w", alert.Host, err)
+		}
+	}
+	return nil
+}
+
+// DedupKey returns the stable key identifying the incident of a host
+func (e *Events) DedupKey(host string) string {
+	return e.config.DedupKey + host
+}
+
+// event builds the trigger or resolve event of an alert
+func (e *Events) event(alert Alert) event {
+	ev := event{
+		RoutingKey: e.config.RoutingKey,
+		DedupKey:   e.DedupKey(alert.Host),
+	}
+
+	if alert.Resolved() {
+		ev.EventAction = "resolve"
+		return ev
+	}
+
+	ev.EventAction = "trigger"
+	ev.Payload = &eventPayload{
+		Summary:       fmt.Sprintf("SSL certificate %s for %s: %s", alert.Status, alert.Host, alert.Reason),
+		Source:        alert.Host,
+		Severity:      "critical",
+		Timestamp:     alert.DetectedAt,
+		Class:         "certificate_expiry",
+		Group:         strings.Join(alert.Tags, ","),
+		CustomDetails: alert,
+	}
+	if alert.Status == cert.StatusError {
+		ev.Payload.Severity = "error"
+		ev.Payload.Class = "check_failed"
+	}
+
+	return ev
+}
+
+// send posts an event
+func (e *Events) send(ctx context.Context, ev event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("cannot create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot send event: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("events endpoint returned %s", resp.Status)
+	}
+
+	return nil
+}
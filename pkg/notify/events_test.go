@@ -0,0 +1,186 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// eventsMock is an events API endpoint recording the events it receives
+type eventsMock struct {
+	server *httptest.Server
+	mutex  sync.Mutex
+	events []map[string]any
+}
+
+func newEventsMock(t *testing.T) *eventsMock {
+	t.Helper()
+
+	m := &eventsMock{}
+	m.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		var ev map[string]any
+		if err := json.Unmarshal(body, &ev); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		m.mutex.Lock()
+		m.events = append(m.events, ev)
+		m.mutex.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+// take returns the events received since the last call
+func (m *eventsMock) take() []map[string]any {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	events := m.events
+	m.events = nil
+	return events
+}
+
+func TestEvents_Process(t *testing.T) {
+	mock := newEventsMock(t)
+	statePath := filepath.Join(t.TempDir(), "alerts.json")
+	now := time.Now()
+
+	// Every run loads the state left by the previous one
+	run := func(result *cert.Result) {
+		t.Helper()
+
+		alerter, err := New(Config{
+			StateFile: statePath,
+			Events:    []EventsConfig{{URL: mock.server.URL, RoutingKey: "R0UT1NG"}},
+		}, cert.DefaultThresholds())
+		if err != nil {
+			t.Fatalf("New() unexpected error: %v", err)
+		}
+		alerter.now = func() time.Time { return now }
+
+		if err := alerter.Process(context.Background(), result); err != nil {
+			t.Fatalf("Process() unexpected error: %v", err)
+		}
+	}
+
+	// Warnings do not open incidents
+	run(testResult(now, map[string]int{"a.example.com:443": 20, "b.example.com:443": 90}))
+	if events := mock.take(); len(events) != 0 {
+		t.Fatalf("warning sent events %v", events)
+	}
+
+	run(testResult(now, map[string]int{"a.example.com:443": 5}, "b.example.com:443"))
+	events := mock.take()
+	if len(events) != 2 {
+		t.Fatalf("critical and failing hosts sent %d event(s), want 2: %v", len(events), events)
+	}
+	for _, ev := range events {
+		payload, _ := ev["payload"].(map[string]any)
+		if ev["event_action"] != "trigger" || ev["routing_key"] != "R0UT1NG" || payload == nil {
+			t.Fatalf("trigger event = %v", ev)
+		}
+		switch ev["dedup_key"] {
+		case "ssl-certs-checker:a.example.com:443":
+			if payload["severity"] != "critical" || payload["class"] != "certificate_expiry" || payload["group"] != "team-a" {
+				t.Errorf("critical event payload = %v", payload)
+			}
+		case "ssl-certs-checker:b.example.com:443":
+			if payload["severity"] != "error" || !strings.Contains(payload["summary"].(string), "connection refused") {
+				t.Errorf("error event payload = %v", payload)
+			}
+		default:
+			t.Errorf("unexpected dedup key in %v", ev)
+		}
+	}
+
+	// Open incidents are not triggered again
+	run(testResult(now, map[string]int{"a.example.com:443": 5}, "b.example.com:443"))
+	if events := mock.take(); len(events) != 0 {
+		t.Fatalf("unchanged hosts sent events %v", events)
+	}
+
+	// Renewed certificates resolve the incident with the same key
+	run(testResult(now, map[string]int{"a.example.com:443": 90}, "b.example.com:443"))
+	events = mock.take()
+	if len(events) != 1 || events[0]["event_action"] != "resolve" || events[0]["dedup_key"] != "ssl-certs-checker:a.example.com:443" {
+		t.Fatalf("renewed host sent events %v", events)
+	}
+	if _, ok := events[0]["payload"]; ok {
+		t.Errorf("resolve event should not have a payload: %v", events[0])
+	}
+}
+
+func TestEvents_Wants(t *testing.T) {
+	events, err := NewEvents(EventsConfig{RoutingKey: "key", Tags: []string{"production"}})
+	if err != nil {
+		t.Fatalf("NewEvents() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		alert Alert
+		want  bool
+	}{
+		{name: "critical", alert: Alert{Status: cert.StatusCritical, Tags: []string{"production"}}, want: true},
+		{name: "error", alert: Alert{Status: cert.StatusError, Tags: []string{"production"}}, want: true},
+		{name: "warning", alert: Alert{Status: cert.StatusWarning, Tags: []string{"production"}}},
+		{name: "resolved", alert: Alert{Status: cert.StatusOK, Previous: cert.StatusCritical, Tags: []string{"production"}}, want: true},
+		{name: "recovered", alert: Alert{Status: cert.StatusOK, Previous: cert.StatusError, Tags: []string{"production"}}, want: true},
+		{name: "warning to ok", alert: Alert{Status: cert.StatusOK, Previous: cert.StatusWarning, Tags: []string{"production"}}},
+		{name: "other tag", alert: Alert{Status: cert.StatusCritical, Tags: []string{"staging"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := events.Wants(tt.alert); got != tt.want {
+				t.Errorf("Wants() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if events.config.URL != DefaultEventsURL {
+		t.Errorf("NewEvents() url = %q, want %q", events.config.URL, DefaultEventsURL)
+	}
+}
+
+func TestEventsConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  EventsConfig
+		wantErr string
+	}{
+		{name: "valid", config: EventsConfig{RoutingKey: "key", URL: "http://localhost:8080/v2/enqueue"}},
+		{name: "missing routing key", config: EventsConfig{}, wantErr: "routing_key is required"},
+		{name: "invalid url", config: EventsConfig{RoutingKey: "key", URL: "events.example.com"}, wantErr: "invalid events url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"net/http"
+	"time"
+)
+
+// EventsConfig configures an events notifier opening incidents for critical
+// and failing hosts and resolving them once the hosts are OK again
+type EventsConfig struct {
+	Name       string        `yaml:"name"`
+	URL        string        `yaml:"url"`
+	RoutingKey string        `yaml:"routing_key"`
+	DedupKey   string        `yaml:"dedup_key_prefix"`
+	Tags       []string      `yaml:"tags"`
+	Timeout    time.Duration `yaml:"timeout"`
+}
+
+// Events sends trigger and resolve events in the PagerDuty Events API v2
+// format
+type Events struct {
+	config EventsConfig
+	client *http.Client
+}
+
+// event is the body of an events API request
+type event struct {
+	RoutingKey  string        `json:"routing_key"`
+	EventAction string        `json:"event_action"`
+	DedupKey    string        `json:"dedup_key"`
+	Payload     *eventPayload `json:"payload,omitempty"`
+}
+
+type eventPayload struct {
+	Summary       string    `json:"summary"`
+	Source        string    `json:"source"`
+	Severity      string    `json:"severity"`
+	Timestamp     time.Time `json:"timestamp"`
+	Class         string    `json:"class"`
+	Group         string    `json:"group,omitempty"`
+	CustomDetails Alert     `json:"custom_details"`
+}
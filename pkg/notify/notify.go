@@ -0,0 +1,331 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/internal/atomicfile"
+)
+
+const stateVersion = 1
+
+// severities orders the statuses, failed checks are as severe as critical
+// certificates
+var severities = map[cert.Status]int{
+	cert.StatusOK:       0,
+	cert.StatusWarning:  1,
+	cert.StatusCritical: 2,
+	cert.StatusError:    2,
+}
+
+// New creates an alerter for the notifiers of the alerts configuration
+func New(cfg Config, thresholds cert.Thresholds) (*Alerter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var notifiers []Notifier
+	for _, webhookConfig := range cfg.Webhooks {
+		webhook, err := NewWebhook(webhookConfig)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, webhook)
+	}
+
+	for _, eventsConfig := range cfg.Events {
+		events, err := NewEvents(eventsConfig)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, events)
+	}
+
+	return NewAlerter(notifiers, thresholds, cfg.StateFile)
+}
+
+// NewAlerter creates an alerter for the given notifiers, the alerts already
+// sent are kept in the state file when a path is given and in memory only
+// otherwise
+func NewAlerter(notifiers []Notifier, thresholds cert.Thresholds, statePath string) (*Alerter, error) {
+	a := &Alerter{
+		notifiers:  notifiers,
+		thresholds: thresholds,
+		statePath:  statePath,
+		state:      alertState{Version: stateVersion, Notifiers: make(map[string]map[string]cert.Status)},
+		now:        time.Now,
+	}
+
+	if statePath == "" {
+		return a, nil
+	}
+
+	data, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return a, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read alert state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &a.state); err != nil {
+		return nil, fmt.Errorf("invalid alert state file %s: %w", statePath, err)
+	}
+	if a.state.Version != stateVersion {
+		return nil, fmt.Errorf("unsupported alert state file version %d in %s", a.state.Version, statePath)
+	}
+	if a.state.Notifiers == nil {
+		a.state.Notifiers = make(map[string]map[string]cert.Status)
+	}
+
+	return a, nil
+}
+
+// Validate checks the alerts configuration
+func (c Config) Validate() error {
+	names := make(map[string]bool)
+
+	for i, webhook := range c.Webhooks {
+		if err := webhook.Validate(); err != nil {
+			return fmt.Errorf("invalid webhook at index %d: %w", i, err)
+		}
+
+		name := webhook.NotifierName()
+		if names[name] {
+			return fmt.Errorf("duplicate notifier name %q, set a unique name", name)
+		}
+		names[name] = true
+	}
+
+	for i, events := range c.Events {
+		if err := events.Validate(); err != nil {
+			return fmt.Errorf("invalid events at index %d: %w", i, err)
+		}
+
+		name := events.NotifierName()
+		if names[name] {
+			return fmt.Errorf("duplicate notifier name %q, set a unique name", name)
+		}
+		names[name] = true
+	}
+
+	for i, email := range c.Email {
+		if err := email.Validate(); err != nil {
+			return fmt.Errorf("invalid email at index %d: %w", i, err)
+		}
+
+		name := email.NotifierName()
+		if names[name] {
+			return fmt.Errorf("duplicate notifier name %q, set a unique name", name)
+		}
+		names[name] = true
+	}
+
+	return nil
+}
+
+// ResolveSecrets replaces the sensitive settings of the notifiers, the
+// webhook URLs and headers, the events URLs and routing keys and the SMTP
+// credentials, with the value returned by resolve
+func (c *Config) ResolveSecrets(resolve func(value string) (string, error)) error {
+	resolveAll := func(what string, values ...*string) error {
+		for _, value := range values {
+			resolved, err := resolve(*value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", what, err)
+			}
+			*value = resolved
+		}
+		return nil
+	}
+
+	for i := range c.Webhooks {
+		webhook := &c.Webhooks[i]
+		if err := resolveAll(fmt.Sprintf("webhook at index %d", i), &webhook.URL); err != nil {
+			return err
+		}
+		for name, value := range webhook.Headers {
+			if err := resolveAll(fmt.Sprintf("header %s of webhook at index %d", name, i), &value); err != nil {
+				return err
+			}
+			webhook.Headers[name] = value
+		}
+	}
+
+	for i := range c.Events {
+		events := &c.Events[i]
+		if err := resolveAll(fmt.Sprintf("events at index %d", i), &events.URL, &events.RoutingKey); err != nil {
+			return err
+		}
+	}
+
+	for i := range c.Email {
+		smtpConfig := &c.Email[i].SMTP
+		if err := resolveAll(fmt.Sprintf("email at index %d", i), &smtpConfig.Username, &smtpConfig.Password); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the policy
+func (p Policy) Validate() error {
+	if p.MinStatus == "" {
+		return nil
+	}
+
+	if _, ok := severities[p.MinStatus]; !ok || p.MinStatus == cert.StatusOK {
+		return fmt.Errorf("invalid min_status: %s (supported: warning, critical, error)", p.MinStatus)
+	}
+
+	return nil
+}
+
+// Wants reports whether an alert passes the policy
+func (p Policy) Wants(alert Alert) bool {
+	if !p.matchesTags(alert) {
+		return false
+	}
+
+	minStatus := p.MinStatus
+	if minStatus == "" {
+		minStatus = cert.StatusWarning
+	}
+
+	if alert.Status == cert.StatusOK {
+		return p.SendResolved && severities[alert.Previous] >= severities[minStatus]
+	}
+
+	return severities[alert.Status] >= severities[minStatus]
+}
+
+// matchesTags reports whether the host of an alert has one of the tags of
+// the policy, any host matches a policy without tags
+func (p Policy) matchesTags(alert Alert) bool {
+	return len(p.Tags) == 0 || slices.ContainsFunc(p.Tags, func(tag string) bool {
+		return slices.Contains(alert.Tags, tag)
+	})
+}
+
+// Resolved reports whether the alert tells that a host is fine again
+func (a Alert) Resolved() bool {
+	return a.Status == cert.StatusOK
+}
+
+// Text describes the alert on a single line
+func (a Alert) Text() string {
+	label := strings.ToUpper(string(a.Status))
+	if a.Resolved() {
+		label = "RESOLVED"
+	}
+	return fmt.Sprintf("[%s] %s: %s", label, a.Host, a.Reason)
+}
+
+// Process determines the hosts whose status changed since the last run and
+// notifies every notifier of the changes it wants. A notifier failing to
+// deliver is told again on the next run.
+func (a *Alerter) Process(ctx context.Context, result *cert.Result) error {
+	alerts := a.alerts(result)
+
+	var errs []error
+	for _, notifier := range a.notifiers {
+		seen := a.state.Notifiers[notifier.Name()]
+
+		var changes, wanted []Alert
+		for _, alert := range alerts {
+			previous, known := seen[alert.Host]
+			if previous == alert.Status || (!known && alert.Status == cert.StatusOK) {
+				if !known {
+					changes = append(changes, alert)
+				}
+				continue
+			}
+
+			alert.Previous = previous
+			changes = append(changes, alert)
+			if notifier.Wants(alert) {
+				wanted = append(wanted, alert)
+			}
+		}
+
+		if len(wanted) > 0 {
+			if err := notifier.Notify(ctx, wanted); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", notifier.Name(), err))
+				continue
+			}
+		}
+
+		if seen == nil {
+			seen = make(map[string]cert.Status)
+			a.state.Notifiers[notifier.Name()] = seen
+		}
+		for _, alert := range changes {
+			seen[alert.Host] = alert.Status
+		}
+	}
+
+	if err := a.save(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// alerts describes the status of every host of a result
+func (a *Alerter) alerts(result *cert.Result) []Alert {
+	now := a.now()
+	alerts := make([]Alert, 0, len(result.Certificates)+len(result.Errors))
+
+	for i := range result.Certificates {
+		certInfo := &result.Certificates[i]
+		status, reason := a.thresholds.Evaluate(*certInfo, now)
+		alerts = append(alerts, Alert{
+			Host:        certInfo.Host,
+			Status:      status,
+			Reason:      reason,
+			Tags:        certInfo.Tags,
+			Certificate: certInfo,
+			DetectedAt:  now,
+		})
+	}
+
+	for i := range result.Errors {
+		errorInfo := &result.Errors[i]
+		alerts = append(alerts, Alert{
+			Host:       errorInfo.Host,
+			Status:     cert.StatusError,
+			Reason:     errorInfo.Error,
+			Tags:       errorInfo.Tags,
+			Error:      errorInfo,
+			DetectedAt: now,
+		})
+	}
+
+	return alerts
+}
+
+// save atomically writes the state file when one is configured
+func (a *Alerter) save() error {
+	if a.statePath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(a.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling alert state: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(a.statePath, append(data, '\n')); err != nil {
+		return fmt.Errorf("cannot write alert state file: %w", err)
+	}
+
+	return nil
+}
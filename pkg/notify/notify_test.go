@@ -0,0 +1,284 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// recorder is a notifier remembering the alerts it was sent
+type recorder struct {
+	name   string
+	policy Policy
+	fail   bool
+	sent   [][]Alert
+}
+
+func (r *recorder) Name() string           { return r.name }
+func (r *recorder) Wants(alert Alert) bool { return r.policy.Wants(alert) }
+
+func (r *recorder) Notify(ctx context.Context, alerts []Alert) error {
+	if r.fail {
+		return errors.New("unavailable")
+	}
+	r.sent = append(r.sent, alerts)
+	return nil
+}
+
+func testResult(now time.Time, days map[string]int, failing ...string) *cert.Result {
+	result := &cert.Result{}
+	for host, left := range days {
+		result.Certificates = append(result.Certificates, cert.CertificateInfo{
+			Host:      host,
+			NotBefore: now.AddDate(0, 0, -30),
+			NotAfter:  now.AddDate(0, 0, left).Add(time.Hour),
+			Tags:      []string{"team-" + strings.Split(host, ".")[0]},
+		})
+	}
+	for _, host := range failing {
+		result.Errors = append(result.Errors, cert.ErrorInfo{Host: host, Error: "connection refused"})
+	}
+	return result
+}
+
+func TestAlerter_Process(t *testing.T) {
+	now := time.Now()
+	ctx := context.Background()
+	notifier := &recorder{name: "test"}
+
+	alerter, err := NewAlerter([]Notifier{notifier}, cert.DefaultThresholds(), "")
+	if err != nil {
+		t.Fatalf("NewAlerter() unexpected error: %v", err)
+	}
+	alerter.now = func() time.Time { return now }
+
+	// Healthy hosts are not reported on the first run
+	if err := alerter.Process(ctx, testResult(now, map[string]int{"a.example.com:443": 90, "b.example.com:443": 20})); err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if len(notifier.sent) != 1 || len(notifier.sent[0]) != 1 || notifier.sent[0][0].Host != "b.example.com:443" || notifier.sent[0][0].Status != cert.StatusWarning {
+		t.Fatalf("first run sent %+v", notifier.sent)
+	}
+
+	// Unchanged statuses are not reported again
+	if err := alerter.Process(ctx, testResult(now, map[string]int{"a.example.com:443": 90, "b.example.com:443": 19})); err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if len(notifier.sent) != 1 {
+		t.Fatalf("second run should not alert, sent %+v", notifier.sent[1:])
+	}
+
+	// Crossing the critical threshold and failing are reported
+	if err := alerter.Process(ctx, testResult(now, map[string]int{"b.example.com:443": 5}, "a.example.com:443")); err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if len(notifier.sent) != 2 || len(notifier.sent[1]) != 2 {
+		t.Fatalf("third run sent %+v", notifier.sent)
+	}
+	for _, alert := range notifier.sent[1] {
+		switch alert.Host {
+		case "a.example.com:443":
+			if alert.Status != cert.StatusError || alert.Previous != cert.StatusOK || alert.Error == nil {
+				t.Errorf("failing host alert = %+v", alert)
+			}
+		case "b.example.com:443":
+			if alert.Status != cert.StatusCritical || alert.Previous != cert.StatusWarning || alert.Certificate == nil {
+				t.Errorf("critical host alert = %+v", alert)
+			}
+		}
+	}
+
+	// Recoveries are only reported when asked for
+	notifier.policy.SendResolved = true
+	if err := alerter.Process(ctx, testResult(now, map[string]int{"a.example.com:443": 90, "b.example.com:443": 5})); err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if len(notifier.sent) != 3 || len(notifier.sent[2]) != 1 || !notifier.sent[2][0].Resolved() {
+		t.Fatalf("fourth run sent %+v", notifier.sent)
+	}
+	if got := notifier.sent[2][0].Text(); !strings.HasPrefix(got, "[RESOLVED] a.example.com:443: ") {
+		t.Errorf("Text() = %q", got)
+	}
+}
+
+func TestAlerter_Process_Policy(t *testing.T) {
+	now := time.Now()
+	critical := &recorder{name: "critical", policy: Policy{MinStatus: cert.StatusCritical}}
+	tagged := &recorder{name: "tagged", policy: Policy{Tags: []string{"team-b"}}}
+
+	alerter, err := NewAlerter([]Notifier{critical, tagged}, cert.DefaultThresholds(), "")
+	if err != nil {
+		t.Fatalf("NewAlerter() unexpected error: %v", err)
+	}
+	alerter.now = func() time.Time { return now }
+
+	result := testResult(now, map[string]int{"a.example.com:443": 20, "b.example.com:443": 5})
+	if err := alerter.Process(context.Background(), result); err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+
+	if len(critical.sent) != 1 || len(critical.sent[0]) != 1 || critical.sent[0][0].Host != "b.example.com:443" {
+		t.Errorf("min_status critical sent %+v", critical.sent)
+	}
+	if len(tagged.sent) != 1 || len(tagged.sent[0]) != 1 || tagged.sent[0][0].Host != "b.example.com:443" {
+		t.Errorf("tag filter sent %+v", tagged.sent)
+	}
+
+	// Warning hosts turning critical are reported once they qualify
+	result = testResult(now, map[string]int{"a.example.com:443": 5, "b.example.com:443": 5})
+	if err := alerter.Process(context.Background(), result); err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if len(critical.sent) != 2 || critical.sent[1][0].Host != "a.example.com:443" {
+		t.Errorf("min_status critical sent %+v", critical.sent)
+	}
+}
+
+func TestAlerter_Process_State(t *testing.T) {
+	now := time.Now()
+	statePath := filepath.Join(t.TempDir(), "alerts.json")
+	result := testResult(now, map[string]int{"a.example.com:443": 5})
+
+	failing := &recorder{name: "test", fail: true}
+	alerter, err := NewAlerter([]Notifier{failing}, cert.DefaultThresholds(), statePath)
+	if err != nil {
+		t.Fatalf("NewAlerter() unexpected error: %v", err)
+	}
+	if err := alerter.Process(context.Background(), result); err == nil || !strings.Contains(err.Error(), "test: unavailable") {
+		t.Fatalf("Process() error = %v, want delivery failure", err)
+	}
+
+	// Undelivered alerts are retried by the next run
+	notifier := &recorder{name: "test"}
+	alerter, err = NewAlerter([]Notifier{notifier}, cert.DefaultThresholds(), statePath)
+	if err != nil {
+		t.Fatalf("NewAlerter() unexpected error: %v", err)
+	}
+	if err := alerter.Process(context.Background(), result); err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if len(notifier.sent) != 1 {
+		t.Fatalf("retry sent %+v", notifier.sent)
+	}
+
+	// Delivered alerts are remembered across runs
+	notifier = &recorder{name: "test"}
+	alerter, err = NewAlerter([]Notifier{notifier}, cert.DefaultThresholds(), statePath)
+	if err != nil {
+		t.Fatalf("NewAlerter() unexpected error: %v", err)
+	}
+	if err := alerter.Process(context.Background(), result); err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if len(notifier.sent) != 0 {
+		t.Errorf("state file should suppress repeated alerts, sent %+v", notifier.sent)
+	}
+}
+
+func TestConfig_ResolveSecrets(t *testing.T) {
+	config := Config{
+		Webhooks: []WebhookConfig{{Type: WebhookGeneric, URL: "secret:url", Headers: map[string]string{"Authorization": "secret:token", "X-Team": "ops"}}},
+		Events:   []EventsConfig{{RoutingKey: "secret:key"}},
+		Email:    []EmailConfig{{SMTP: SMTPConfig{Username: "reporter", Password: "secret:password"}}},
+	}
+
+	resolve := func(value string) (string, error) {
+		if name, ok := strings.CutPrefix(value, "secret:"); ok {
+			return "resolved-" + name, nil
+		}
+		return value, nil
+	}
+	if err := config.ResolveSecrets(resolve); err != nil {
+		t.Fatalf("ResolveSecrets() unexpected error: %v", err)
+	}
+
+	webhook := config.Webhooks[0]
+	if webhook.URL != "resolved-url" || webhook.Headers["Authorization"] != "resolved-token" || webhook.Headers["X-Team"] != "ops" {
+		t.Errorf("ResolveSecrets() webhook = %+v", webhook)
+	}
+	if key := config.Events[0].RoutingKey; key != "resolved-key" {
+		t.Errorf("ResolveSecrets() routing key = %q", key)
+	}
+	if smtpConfig := config.Email[0].SMTP; smtpConfig.Username != "reporter" || smtpConfig.Password != "resolved-password" {
+		t.Errorf("ResolveSecrets() smtp = %+v", smtpConfig)
+	}
+
+	failing := func(value string) (string, error) { return "", errors.New("secret not found") }
+	if err := config.ResolveSecrets(failing); err == nil || !strings.Contains(err.Error(), "webhook at index 0") {
+		t.Errorf("ResolveSecrets() error = %v, want it to point to the webhook", err)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{
+			name: "valid",
+			config: Config{Webhooks: []WebhookConfig{
+				{Type: WebhookSlack, URL: "https://hooks.example.com/slack"},
+				{Type: WebhookGeneric, URL: "http://localhost:8080/hook", Body: `{"text": {{json .Text}}}`, Policy: Policy{MinStatus: cert.StatusCritical}},
+			}},
+		},
+		{
+			name:    "unknown type",
+			config:  Config{Webhooks: []WebhookConfig{{Type: "irc", URL: "https://example.com"}}},
+			wantErr: "invalid webhook type",
+		},
+		{
+			name:    "missing url",
+			config:  Config{Webhooks: []WebhookConfig{{Type: WebhookTeams}}},
+			wantErr: "webhook url is required",
+		},
+		{
+			name:    "invalid url",
+			config:  Config{Webhooks: []WebhookConfig{{Type: WebhookTeams, URL: "ftp://example.com"}}},
+			wantErr: "invalid webhook url",
+		},
+		{
+			name:    "body for slack",
+			config:  Config{Webhooks: []WebhookConfig{{Type: WebhookSlack, URL: "https://example.com", Body: "{}"}}},
+			wantErr: "only supported by generic webhooks",
+		},
+		{
+			name:    "invalid template",
+			config:  Config{Webhooks: []WebhookConfig{{Type: WebhookGeneric, URL: "https://example.com", Body: "{{.Alerts"}}},
+			wantErr: "invalid body template",
+		},
+		{
+			name:    "invalid min status",
+			config:  Config{Webhooks: []WebhookConfig{{Type: WebhookSlack, URL: "https://example.com", Policy: Policy{MinStatus: cert.StatusOK}}}},
+			wantErr: "invalid min_status",
+		},
+		{
+			name: "duplicate names",
+			config: Config{Webhooks: []WebhookConfig{
+				{Type: WebhookSlack, URL: "https://example.com/a"},
+				{Type: WebhookSlack, URL: "https://example.com/b"},
+			}},
+			wantErr: `duplicate notifier name "slack"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
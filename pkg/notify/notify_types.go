@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// Config is the alerts section of the config file
+type Config struct {
+	StateFile string          `yaml:"state_file"`
+	Webhooks  []WebhookConfig `yaml:"webhooks"`
+	Events    []EventsConfig  `yaml:"events"`
+	Email     []EmailConfig   `yaml:"email"`
+}
+
+// Policy selects the alerts a notifier is interested in
+type Policy struct {
+	MinStatus    cert.Status `yaml:"min_status"`
+	SendResolved bool        `yaml:"send_resolved"`
+	Tags         []string    `yaml:"tags"`
+}
+
+// Alert reports that the status of a host changed
+type Alert struct {
+	Host        string                `json:"host"`
+	Status      cert.Status           `json:"status"`
+	Previous    cert.Status           `json:"previous,omitempty"`
+	Reason      string                `json:"reason"`
+	Tags        []string              `json:"tags,omitempty"`
+	Certificate *cert.CertificateInfo `json:"certificate,omitempty"`
+	Error       *cert.ErrorInfo       `json:"error,omitempty"`
+	DetectedAt  time.Time             `json:"detected_at"`
+}
+
+// Notifier delivers alerts to a destination
+type Notifier interface {
+	Name() string
+	Wants(alert Alert) bool
+	Notify(ctx context.Context, alerts []Alert) error
+}
+
+// Alerter turns the results of runs into alerts for its notifiers,
+// remembering what every notifier was told to avoid repeated alerts
+type Alerter struct {
+	notifiers  []Notifier
+	thresholds cert.Thresholds
+	statePath  string
+	state      alertState
+	now        func() time.Time
+}
+
+type alertState struct {
+	Version   int                               `json:"version"`
+	Notifiers map[string]map[string]cert.Status `json:"notifiers"`
+}
@@ -0,0 +1,199 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+var webhookTypes = []string{WebhookSlack, WebhookTeams, WebhookGeneric}
+
+// teamsColors are the theme colors of Teams message cards by status
+var teamsColors = map[cert.Status]string{
+	cert.StatusOK:       "2EB886",
+	cert.StatusWarning:  "DAA038",
+	cert.StatusCritical: "A30200",
+	cert.StatusError:    "A30200",
+}
+
+var bodyFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper": func(v any) string { return strings.ToUpper(fmt.Sprint(v)) },
+	"lower": func(v any) string { return strings.ToLower(fmt.Sprint(v)) },
+	"join":  strings.Join,
+}
+
+// NewWebhook creates a webhook notifier
+func NewWebhook(cfg WebhookConfig) (*Webhook, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	w := &Webhook{config: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+	if w.client.Timeout == 0 {
+		w.client.Timeout = defaultWebhookTimeout
+	}
+
+	if cfg.Body != "" {
+		body, err := template.New(cfg.NotifierName()).Funcs(bodyFuncs).Parse(cfg.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body template: %w", err)
+		}
+		w.body = body
+	}
+
+	return w, nil
+}
+
+// Validate checks the webhook configuration
+func (c WebhookConfig) Validate() error {
+	if !slices.Contains(webhookTypes, c.Type) {
+		return fmt.Errorf("invalid webhook type: %q (supported: %s)", c.Type, strings.Join(webhookTypes, ", "))
+	}
+
+	if c.URL == "" {
+		return fmt.Errorf("webhook url is required")
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook url: %q", c.URL)
+	}
+
+	if c.Body != "" {
+		if c.Type != WebhookGeneric {
+			return fmt.Errorf("body is only supported by generic webhooks")
+		}
+		if _, err := template.New("body").Funcs(bodyFuncs).Parse(c.Body); err != nil {
+			return fmt.Errorf("invalid body template: %w", err)
+		}
+	}
+
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+
+	return c.Policy.Validate()
+}
+
+// NotifierName returns the configured name, defaulting to the webhook type
+func (c WebhookConfig) NotifierName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Type
+}
+
+// Name returns the name of the notifier
+func (w *Webhook) Name() string {
+	return w.config.NotifierName()
+}
+
+// Wants reports whether the webhook is interested in an alert
+func (w *Webhook) Wants(alert Alert) bool {
+	return w.config.Policy.Wants(alert)
+}
+
+// Notify posts the alerts in a single request
+func (w *Webhook) Notify(ctx context.Context, alerts []Alert) error {
+	payload, err := w.payload(alerts)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("cannot create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range w.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+// payload renders the request body for the webhook type
+func (w *Webhook) payload(alerts []Alert) ([]byte, error) {
+	text := alertsText(alerts)
+
+	switch w.config.Type {
+	case WebhookSlack:
+		return json.Marshal(map[string]string{"text": text})
+	case WebhookTeams:
+		return json.Marshal(map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    alertsTitle(alerts),
+			"title":      alertsTitle(alerts),
+			"themeColor": teamsColors[worstStatus(alerts)],
+			"text":       strings.ReplaceAll(alertsLines(alerts), "\n", "<br>"),
+		})
+	}
+
+	if w.body == nil {
+		return json.Marshal(map[string]any{"alerts": alerts})
+	}
+
+	var buf bytes.Buffer
+	if err := w.body.Execute(&buf, webhookData{Alerts: alerts, Text: text}); err != nil {
+		return nil, fmt.Errorf("error executing body template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// alertsTitle summarizes a batch of alerts
+func alertsTitle(alerts []Alert) string {
+	return fmt.Sprintf("SSL certificate alerts: %d host(s) changed status", len(alerts))
+}
+
+// alertsLines describes every alert on its own line
+func alertsLines(alerts []Alert) string {
+	lines := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		lines = append(lines, alert.Text())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// alertsText is the plain text message for a batch of alerts
+func alertsText(alerts []Alert) string {
+	return alertsTitle(alerts) + "\n" + alertsLines(alerts)
+}
+
+// worstStatus returns the most severe status of a batch of alerts
+func worstStatus(alerts []Alert) cert.Status {
+	worst := cert.StatusOK
+	for _, alert := range alerts {
+		if severities[alert.Status] > severities[worst] {
+			worst = alert.Status
+		}
+	}
+	return worst
+}
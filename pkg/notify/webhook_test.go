@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// receiver is an HTTP endpoint recording the requests it receives
+type receiver struct {
+	server *httptest.Server
+	bodies []string
+	header http.Header
+}
+
+func newReceiver(t *testing.T, status int) *receiver {
+	t.Helper()
+
+	r := &receiver{}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.bodies = append(r.bodies, string(body))
+		r.header = req.Header
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.server.Close)
+
+	return r
+}
+
+func testAlerts() []Alert {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []Alert{
+		{Host: "a.example.com:443", Status: cert.StatusCritical, Previous: cert.StatusWarning, Reason: "certificate expires in 3 day(s)", DetectedAt: now},
+		{Host: "b.example.com:443", Status: cert.StatusError, Reason: "connection refused", DetectedAt: now},
+	}
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   WebhookConfig
+		check func(t *testing.T, payload map[string]any)
+	}{
+		{
+			name: "slack",
+			cfg:  WebhookConfig{Type: WebhookSlack},
+			check: func(t *testing.T, payload map[string]any) {
+				text, _ := payload["text"].(string)
+				if !strings.Contains(text, "[CRITICAL] a.example.com:443: certificate expires in 3 day(s)") || !strings.Contains(text, "[ERROR] b.example.com:443") {
+					t.Errorf("slack text = %q", text)
+				}
+			},
+		},
+		{
+			name: "teams",
+			cfg:  WebhookConfig{Type: WebhookTeams},
+			check: func(t *testing.T, payload map[string]any) {
+				if payload["@type"] != "MessageCard" || payload["themeColor"] != "A30200" {
+					t.Errorf("teams card = %v", payload)
+				}
+				if text, _ := payload["text"].(string); !strings.Contains(text, "<br>") {
+					t.Errorf("teams text = %q", text)
+				}
+			},
+		},
+		{
+			name: "generic default body",
+			cfg:  WebhookConfig{Type: WebhookGeneric},
+			check: func(t *testing.T, payload map[string]any) {
+				alerts, _ := payload["alerts"].([]any)
+				if len(alerts) != 2 {
+					t.Fatalf("generic alerts = %v", payload)
+				}
+				if first, _ := alerts[0].(map[string]any); first["host"] != "a.example.com:443" || first["previous"] != "warning" {
+					t.Errorf("generic alert = %v", first)
+				}
+			},
+		},
+		{
+			name: "generic template",
+			cfg: WebhookConfig{
+				Type:    WebhookGeneric,
+				Headers: map[string]string{"Authorization": "Bearer secret"},
+				Body:    `{"hosts": [{{range $i, $a := .Alerts}}{{if $i}}, {{end}}{{json $a.Host}}{{end}}], "severity": {{json (upper (index .Alerts 0).Status)}}}`,
+			},
+			check: func(t *testing.T, payload map[string]any) {
+				hosts, _ := payload["hosts"].([]any)
+				if len(hosts) != 2 || hosts[1] != "b.example.com:443" || payload["severity"] != "CRITICAL" {
+					t.Errorf("generic payload = %v", payload)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newReceiver(t, http.StatusOK)
+			tt.cfg.URL = r.server.URL
+
+			webhook, err := NewWebhook(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewWebhook() unexpected error: %v", err)
+			}
+			if err := webhook.Notify(context.Background(), testAlerts()); err != nil {
+				t.Fatalf("Notify() unexpected error: %v", err)
+			}
+
+			if len(r.bodies) != 1 {
+				t.Fatalf("receiver got %d requests, want 1", len(r.bodies))
+			}
+			if got := r.header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			for name, value := range tt.cfg.Headers {
+				if got := r.header.Get(name); got != value {
+					t.Errorf("header %s = %q, want %q", name, got, value)
+				}
+			}
+
+			var payload map[string]any
+			if err := json.Unmarshal([]byte(r.bodies[0]), &payload); err != nil {
+				t.Fatalf("payload is not JSON: %v\n%s", err, r.bodies[0])
+			}
+			tt.check(t, payload)
+		})
+	}
+}
+
+func TestWebhook_Notify_Failure(t *testing.T) {
+	r := newReceiver(t, http.StatusInternalServerError)
+
+	webhook, err := NewWebhook(WebhookConfig{Type: WebhookSlack, URL: r.server.URL})
+	if err != nil {
+		t.Fatalf("NewWebhook() unexpected error: %v", err)
+	}
+
+	err = webhook.Notify(context.Background(), testAlerts())
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Notify() error = %v, want status error", err)
+	}
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"net/http"
+	"text/template"
+	"time"
+)
+
+// Webhook types
+const (
+	WebhookSlack   = "slack"
+	WebhookTeams   = "teams"
+	WebhookGeneric = "generic"
+)
+
+// WebhookConfig configures a webhook notifier
+type WebhookConfig struct {
+	Name    string            `yaml:"name"`
+	Type    string            `yaml:"type"`
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+	Body    string            `yaml:"body"`
+	Timeout time.Duration     `yaml:"timeout"`
+	Policy  `yaml:",inline"`
+}
+
+// Webhook posts alerts to Slack, Microsoft Teams or a generic HTTP endpoint
+type Webhook struct {
+	config WebhookConfig
+	body   *template.Template
+	client *http.Client
+}
+
+// webhookData is the data available to the body template of generic webhooks
+type webhookData struct {
+	Alerts []Alert
+	Text   string
+}
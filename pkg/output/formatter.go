@@ -3,98 +3,166 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"strings"
+	"time"
 
 	"go.yaml.in/yaml/v3"
 
 	"github.com/guessi/ssl-certs-checker/pkg/cert"
-	"github.com/jedib0t/go-pretty/v6/table"
-	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/guessi/ssl-certs-checker/pkg/internal/atomicfile"
 )
 
 // NewFormatter creates a new output formatter
 func New() *Formatter {
-	return &Formatter{}
+	return NewWithOptions(Options{
+		Thresholds: cert.DefaultThresholds(),
+	})
+}
+
+// NewWithOptions creates a new output formatter with the given options
+func NewWithOptions(options Options) *Formatter {
+	return &Formatter{
+		options: options,
+		now:     time.Now,
+	}
 }
 
 // Format formats the certificate results according to the specified format
+// and writes them to the configured output writer
 func (f *Formatter) Format(result *cert.Result, format string) error {
-	switch format {
-	case "json":
-		return f.formatJSON(result)
-	case "yaml":
-		return f.formatYAML(result)
-	case "table", "":
-		return f.formatTable(result)
-	default:
-		return fmt.Errorf("unsupported output format: %s", format)
+	return f.format(f.stdout(), f.stderr(), result, format)
+}
+
+// FormatTo formats the certificate results according to the specified format
+// and writes them to w, diagnostics included. The output is never colored,
+// color is only decided for the configured output writer.
+func (f *Formatter) FormatTo(w io.Writer, result *cert.Result, format string) error {
+	return f.withoutColor().format(w, w, result, format)
+}
+
+// WriteFile atomically writes the formatted certificate results to path
+func (f *Formatter) WriteFile(path string, result *cert.Result, format string) error {
+	return atomicfile.Write(path, func(w io.Writer) error {
+		return f.FormatTo(w, result, format)
+	})
+}
+
+// format writes the results to w and diagnostics that are not part of the
+// format itself to diag
+func (f *Formatter) format(w, diag io.Writer, result *cert.Result, name string) error {
+	format, ok := f.Lookup(name)
+	if !ok {
+		return fmt.Errorf("unsupported output format: %s", name)
+	}
+
+	if !format.Capabilities.SupportsErrors {
+		writeErrors(diag, result.Errors)
 	}
+
+	return format.Render(f, w, result)
 }
 
-// formatJSON outputs the results in JSON format
-func (f *Formatter) formatJSON(result *cert.Result) error {
-	jsonOutput, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return fmt.Errorf("error marshaling JSON: %w", err)
+// Lookup returns the format the formatter uses for name
+func (f *Formatter) Lookup(name string) (Format, bool) {
+	if name == "" {
+		name = DefaultFormat
+	}
+	return f.Registry().Lookup(name)
+}
+
+// NewStream creates a stream writing results in the given format to the
+// configured output writer as the checks complete
+func (f *Formatter) NewStream(name string) (Stream, error) {
+	format, ok := f.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unsupported output format: %s", name)
 	}
 
-	fmt.Println(string(jsonOutput))
-	return nil
+	if !format.Capabilities.Streaming {
+		return nil, fmt.Errorf("output format %s does not support streaming", name)
+	}
+
+	return format.NewStream(f, f.stdout()), nil
 }
 
-// formatYAML outputs the results in YAML format
-func (f *Formatter) formatYAML(result *cert.Result) error {
-	yamlOutput, err := yaml.Marshal(result)
-	if err != nil {
-		return fmt.Errorf("error marshaling YAML: %w", err)
+// Options returns the options the formatter was created with
+func (f *Formatter) Options() Options {
+	return f.options
+}
+
+// Now returns the reference time used to evaluate certificates
+func (f *Formatter) Now() time.Time {
+	return f.now()
+}
+
+// Summarize computes the statistics of a result with the configured thresholds
+func (f *Formatter) Summarize(result *cert.Result) cert.Summary {
+	return f.options.Thresholds.Summarize(result, f.now())
+}
+
+// withSummary returns a copy of the result holding its statistics
+func (f *Formatter) withSummary(result *cert.Result) *cert.Result {
+	summary := f.Summarize(result)
+	withSummary := *result
+	withSummary.Summary = &summary
+	return &withSummary
+}
+
+// withoutColor returns a copy of the formatter writing uncolored output
+func (f *Formatter) withoutColor() *Formatter {
+	plain := *f
+	plain.options.Color = false
+	return &plain
+}
+
+// colorEnabled reports whether output may be colored, NO_COLOR always wins
+func (f *Formatter) colorEnabled() bool {
+	return f.options.Color && os.Getenv("NO_COLOR") == ""
+}
+
+// Registry returns the registry formats are looked up in
+func (f *Formatter) Registry() *Registry {
+	if f.options.Registry != nil {
+		return f.options.Registry
 	}
+	return defaultRegistry
+}
 
-	fmt.Println(string(yamlOutput))
-	return nil
-}
-
-// formatTable outputs the results in table format
-func (f *Formatter) formatTable(result *cert.Result) error {
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{
-		"Host",
-		"Common Name",
-		"DNS Names",
-		"Not Before",
-		"Not After",
-		"PublicKeyAlgorithm",
-		"Issuer",
-	})
+// stdout returns the writer results are written to
+func (f *Formatter) stdout() io.Writer {
+	if f.options.Out != nil {
+		return f.options.Out
+	}
+	return os.Stdout
+}
 
-	for _, certInfo := range result.Certificates {
-		dnsNames := ""
-		if len(certInfo.DNSNames) > 0 {
-			dnsNames = strings.Join(certInfo.DNSNames, "\n")
-		}
-
-		t.AppendRows([]table.Row{{
-			certInfo.Host,
-			certInfo.CommonName,
-			dnsNames,
-			certInfo.NotBefore,
-			certInfo.NotAfter,
-			certInfo.PublicKeyAlgorithm,
-			certInfo.Issuer,
-		}})
+// stderr returns the writer diagnostics are written to
+func (f *Formatter) stderr() io.Writer {
+	if f.options.Err != nil {
+		return f.options.Err
 	}
+	return os.Stderr
+}
 
-	if len(result.Errors) > 0 {
-		fmt.Fprintf(os.Stderr, "\nErrors encountered:\n")
-		for _, errInfo := range result.Errors {
-			fmt.Fprintf(os.Stderr, "  %s: %s\n", errInfo.Host, errInfo.Error)
-		}
-		fmt.Fprintf(os.Stderr, "\n")
+// formatJSON outputs the results in JSON format
+func (f *Formatter) formatJSON(w io.Writer, result *cert.Result) error {
+	jsonOutput, err := json.MarshalIndent(f.withSummary(result), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %w", err)
 	}
 
-	t.Style().Format.Header = text.FormatDefault
-	t.Render()
+	_, err = fmt.Fprintln(w, string(jsonOutput))
+	return err
+}
+
+// formatYAML outputs the results in YAML format
+func (f *Formatter) formatYAML(w io.Writer, result *cert.Result) error {
+	yamlOutput, err := yaml.Marshal(f.withSummary(result))
+	if err != nil {
+		return fmt.Errorf("error marshaling YAML: %w", err)
+	}
 
-	return nil
+	_, err = fmt.Fprintln(w, string(yamlOutput))
+	return err
 }
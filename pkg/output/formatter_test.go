@@ -1,9 +1,11 @@
 package output
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -225,3 +227,185 @@ func TestFormatter_Format_DefaultFormat(t *testing.T) {
 		t.Error("Default format should produce table output")
 	}
 }
+
+func TestFormatter_Format_Writers(t *testing.T) {
+	var out, diag bytes.Buffer
+	formatter := NewWithOptions(Options{Out: &out, Err: &diag})
+
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{Host: "example.com:443", CommonName: "example.com"},
+		},
+		Errors: []cert.ErrorInfo{
+			{Host: "invalid.com:443", Error: "connection failed"},
+		},
+	}
+
+	if err := formatter.Format(result, "table"); err != nil {
+		t.Fatalf("Format() unexpected error: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "example.com:443") || strings.Contains(out.String(), "invalid.com:443") {
+		t.Errorf("results writer got unexpected output:\n%s", out.String())
+	}
+	if !strings.Contains(diag.String(), "invalid.com:443: connection failed") {
+		t.Errorf("diagnostics writer got unexpected output:\n%s", diag.String())
+	}
+}
+
+func TestFormatter_FormatTo(t *testing.T) {
+	formatter := New()
+
+	result := &cert.Result{
+		Errors: []cert.ErrorInfo{
+			{Host: "invalid.com:443", Error: "connection failed"},
+		},
+	}
+
+	// Diagnostics are kept together with the results
+	var buf bytes.Buffer
+	if err := formatter.FormatTo(&buf, result, "table"); err != nil {
+		t.Fatalf("FormatTo() unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "invalid.com:443: connection failed") {
+		t.Errorf("FormatTo() should include errors:\n%s", buf.String())
+	}
+}
+
+func TestFormatter_WriteFile(t *testing.T) {
+	tempDir := t.TempDir()
+	formatter := New()
+
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{Host: "example.com:443", CommonName: "example.com"},
+		},
+	}
+
+	path := filepath.Join(tempDir, "report.json")
+	if err := os.WriteFile(path, []byte("stale"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := formatter.WriteFile(path, result, "json"); err != nil {
+		t.Fatalf("WriteFile() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	var jsonResult cert.Result
+	if err := json.Unmarshal(data, &jsonResult); err != nil {
+		t.Fatalf("WriteFile() produced invalid JSON: %v", err)
+	}
+	if len(jsonResult.Certificates) != 1 {
+		t.Errorf("WriteFile() certificates count = %d, want 1", len(jsonResult.Certificates))
+	}
+
+	if err := formatter.WriteFile(filepath.Join(tempDir, "report.txt"), result, "xml"); err == nil {
+		t.Error("WriteFile() should return error for unsupported format")
+	}
+
+	entries, _ := os.ReadDir(tempDir)
+	if len(entries) != 1 {
+		t.Errorf("WriteFile() left unexpected files behind: %v", entries)
+	}
+}
+
+func TestFormatter_WriteFile_Color(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	now := time.Now()
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{Host: "warning.example.com:443", CommonName: "warning.example.com", NotBefore: now.Add(-time.Hour), NotAfter: now.AddDate(0, 0, 10)},
+		},
+	}
+
+	var stdout bytes.Buffer
+	formatter := NewWithOptions(Options{
+		Thresholds: cert.DefaultThresholds(),
+		Template:   `{{range .Certificates}}{{color "yellow" .Host}}{{end}}`,
+		Color:      true,
+		Highlight:  map[string]bool{"warning.example.com:443": true},
+		Out:        &stdout,
+	})
+
+	if err := formatter.Format(result, "table"); err != nil {
+		t.Fatalf("Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "\033[") {
+		t.Errorf("Format() should color the output writer:\n%s", stdout.String())
+	}
+
+	// Files are never colored, whatever the output writer supports
+	for _, format := range []string{"table", "template"} {
+		path := filepath.Join(t.TempDir(), "report.txt")
+		if err := formatter.WriteFile(path, result, format); err != nil {
+			t.Fatalf("WriteFile() unexpected error: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read file: %v", err)
+		}
+		if strings.Contains(string(data), "\033[") {
+			t.Errorf("WriteFile() %s output should not be colored:\n%s", format, data)
+		}
+		if !strings.Contains(string(data), "warning.example.com:443") {
+			t.Errorf("WriteFile() %s output should contain the host:\n%s", format, data)
+		}
+	}
+}
+
+func TestFormatter_Format_Summary(t *testing.T) {
+	now := time.Now()
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{Host: "a.example.com:443", Issuer: "Test CA", PublicKeyAlgorithm: "RSA", SerialNumber: "0A", NotBefore: now.Add(-time.Hour), NotAfter: now.AddDate(0, 0, 90)},
+			{Host: "b.example.com:443", Issuer: "Test CA", PublicKeyAlgorithm: "RSA", SerialNumber: "0A", NotBefore: now.Add(-time.Hour), NotAfter: now.AddDate(0, 0, 10)},
+		},
+		Errors: []cert.ErrorInfo{
+			{Host: "invalid.com:443", Error: "connection failed"},
+		},
+	}
+
+	var buf bytes.Buffer
+	formatter := NewWithOptions(Options{Thresholds: cert.DefaultThresholds(), Out: &buf})
+
+	if err := formatter.Format(result, "json"); err != nil {
+		t.Fatalf("Format() unexpected error: %v", err)
+	}
+
+	var jsonResult cert.Result
+	if err := json.Unmarshal(buf.Bytes(), &jsonResult); err != nil {
+		t.Fatalf("Format() produced invalid JSON: %v", err)
+	}
+
+	summary := jsonResult.Summary
+	if summary == nil {
+		t.Fatal("JSON output should contain a summary")
+	}
+	if summary.Total != 3 || summary.OK != 1 || summary.Warning != 1 || summary.Errors != 1 {
+		t.Errorf("JSON summary counts = %+v", summary)
+	}
+	if summary.SoonestExpiry == nil || summary.SoonestExpiry.Host != "b.example.com:443" {
+		t.Errorf("JSON summary soonest expiry = %+v", summary.SoonestExpiry)
+	}
+	if summary.Issuers["Test CA"] != 2 || summary.KeyAlgorithms["RSA"] != 2 || len(summary.SharedSerials["0A"]) != 2 {
+		t.Errorf("JSON summary breakdown = %+v", summary)
+	}
+	if result.Summary != nil {
+		t.Error("Format() should not modify the result")
+	}
+
+	buf.Reset()
+	if err := formatter.Format(result, "yaml"); err != nil {
+		t.Fatalf("Format() unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "summary:") || !strings.Contains(buf.String(), "total: 3") {
+		t.Errorf("YAML output should contain a summary:\n%s", buf.String())
+	}
+}
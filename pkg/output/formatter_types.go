@@ -1,3 +1,43 @@
 package output
 
-type Formatter struct{}
+import (
+	"io"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+type Options struct {
+	Thresholds cert.Thresholds
+	Template   string
+
+	// Color enables colored output on the output writer, it is disabled
+	// regardless when the NO_COLOR environment variable is set and for
+	// output written anywhere else
+	Color bool
+	// TimeFormat is the layout of timestamps in the table, either a Go
+	// layout or one of RFC3339, RFC1123, DateOnly and DateTime
+	TimeFormat string
+	// Location is the time zone of timestamps in the table, UTC when nil
+	Location *time.Location
+	// Compact truncates long DNS name lists in the table
+	Compact bool
+	// GroupBy groups the table rows by the value of this label, shown in
+	// the first column
+	GroupBy string
+	// Highlight marks the table rows of these hosts, in bold when colored
+	// and with a leading "*" otherwise
+	Highlight map[string]bool
+
+	// Out receives the formatted results, os.Stdout when nil
+	Out io.Writer
+	// Err receives diagnostics such as table errors, os.Stderr when nil
+	Err io.Writer
+	// Registry resolves format names, the default registry when nil
+	Registry *Registry
+}
+
+type Formatter struct {
+	options Options
+	now     func() time.Time
+}
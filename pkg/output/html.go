@@ -0,0 +1,96 @@
+package output
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// htmlColors are the background colors of the status cells
+var htmlColors = map[cert.Status]string{
+	cert.StatusOK:       "#dff0d8",
+	cert.StatusWarning:  "#fcf8e3",
+	cert.StatusCritical: "#f2dede",
+}
+
+// The report only uses inline styles, mail clients ignore style sheets
+var htmlTemplate = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>SSL Certificate Report</title>
+</head>
+<body style="font-family: sans-serif; font-size: 14px;">
+<h2>SSL Certificate Report</h2>
+<p>Generated at {{.GeneratedAt}}</p>
+<p>{{range $i, $line := .Summary}}{{if $i}}<br>{{end}}{{$line}}{{end}}</p>
+{{- if .Certificates}}
+<table cellpadding="4" cellspacing="0" border="1" style="border-collapse: collapse;">
+<tr><th>Host</th><th>Common Name</th><th>Not After</th><th>Days Left</th><th>Expires</th><th>Status</th><th>Issuer</th></tr>
+{{- range .Certificates}}
+<tr><td>{{.Host}}</td><td>{{.CommonName}}</td><td>{{.NotAfter}}</td><td align="right">{{.DaysLeft}}</td><td>{{.Expires}}</td><td style="background-color: {{.Color}};">{{.Status}}</td><td>{{.Issuer}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Errors}}
+<h3>Errors</h3>
+<table cellpadding="4" cellspacing="0" border="1" style="border-collapse: collapse;">
+<tr><th>Host</th><th>Error</th></tr>
+{{- range .Errors}}
+<tr><td>{{.Host}}</td><td>{{.Error}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Rotations}}
+<h3>Certificate Rotations</h3>
+<table cellpadding="4" cellspacing="0" border="1" style="border-collapse: collapse;">
+<tr><th>Host</th><th>Detected At</th><th>Old Certificate</th><th>New Certificate</th></tr>
+{{- range .Rotations}}
+<tr><td>{{.Host}}</td><td>{{.DetectedAt}}</td><td>{{.Old}}</td><td>{{.New}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// FormatHTML writes the results to w as a standalone HTML report, the body of
+// email reports. It is not an output format of the registry.
+func (f *Formatter) FormatHTML(w io.Writer, result *cert.Result) error {
+	now := f.now()
+
+	report := htmlReport{
+		GeneratedAt: f.FormatTimestamp(now),
+		Summary:     f.summaryLines(f.Summarize(result)),
+	}
+
+	for _, certInfo := range result.Certificates {
+		status, _ := f.options.Thresholds.Evaluate(certInfo, now)
+		report.Certificates = append(report.Certificates, htmlCertificate{
+			Host:       certInfo.Host,
+			CommonName: certInfo.CommonName,
+			NotAfter:   f.FormatTimestamp(certInfo.NotAfter),
+			DaysLeft:   certInfo.DaysLeft(now),
+			Expires:    relativeExpiry(certInfo, now),
+			Status:     string(status),
+			Color:      htmlColors[status],
+			Issuer:     certInfo.Issuer,
+		})
+	}
+
+	for _, errInfo := range result.Errors {
+		report.Errors = append(report.Errors, htmlError{Host: errInfo.Host, Error: errInfo.Error})
+	}
+
+	for _, rotation := range result.Rotations {
+		report.Rotations = append(report.Rotations, htmlRotation{
+			Host:       rotation.Host,
+			DetectedAt: f.FormatTimestamp(rotation.DetectedAt),
+			Old:        "Serial " + rotation.Old.SerialNumber,
+			New:        "Serial " + rotation.New.SerialNumber,
+		})
+	}
+
+	return htmlTemplate.Execute(w, report)
+}
@@ -0,0 +1,41 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+func TestFormatter_FormatHTML(t *testing.T) {
+	now := time.Now()
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{Host: "example.com:443", CommonName: "<example>", NotBefore: now.AddDate(0, 0, -10), NotAfter: now.AddDate(0, 0, 5).Add(time.Hour), Issuer: "Test CA"},
+		},
+		Errors: []cert.ErrorInfo{
+			{Host: "invalid.com:443", Error: "connection failed"},
+		},
+	}
+
+	var buf bytes.Buffer
+	formatter := NewWithOptions(Options{Thresholds: cert.DefaultThresholds()})
+	if err := formatter.FormatHTML(&buf, result); err != nil {
+		t.Fatalf("FormatHTML() unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<td>example.com:443</td><td>&lt;example&gt;</td>",
+		`<td style="background-color: #f2dede;">critical</td>`,
+		"<td>invalid.com:443</td><td>connection failed</td>",
+		"2 host(s): 0 ok, 0 warning, 1 critical, 1 error(s)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML output should contain %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package output
+
+// htmlReport is the data of the HTML report template
+type htmlReport struct {
+	GeneratedAt  string
+	Summary      []string
+	Certificates []htmlCertificate
+	Errors       []htmlError
+	Rotations    []htmlRotation
+}
+
+type htmlCertificate struct {
+	Host       string
+	CommonName string
+	NotAfter   string
+	DaysLeft   int
+	Expires    string
+	Status     string
+	Color      string
+	Issuer     string
+}
+
+type htmlError struct {
+	Host  string
+	Error string
+}
+
+type htmlRotation struct {
+	Host       string
+	DetectedAt string
+	Old        string
+	New        string
+}
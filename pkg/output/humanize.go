@@ -0,0 +1,71 @@
+package output
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+var namedLayouts = map[string]string{
+	"RFC3339":  time.RFC3339,
+	"RFC1123":  time.RFC1123,
+	"DateOnly": time.DateOnly,
+	"DateTime": time.DateTime,
+}
+
+// resolveLayout returns the Go time layout for a layout name or the layout itself
+func resolveLayout(layout string) string {
+	if named, ok := namedLayouts[layout]; ok {
+		return named
+	}
+	return layout
+}
+
+// humanizeDuration describes a duration in its largest whole unit, e.g. "12 days"
+func humanizeDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+
+	switch {
+	case d >= 24*time.Hour:
+		return pluralize(int(d/(24*time.Hour)), "day")
+	case d >= time.Hour:
+		return pluralize(int(d/time.Hour), "hour")
+	case d >= time.Minute:
+		return pluralize(int(d/time.Minute), "minute")
+	default:
+		return "less than a minute"
+	}
+}
+
+// relativeTime describes t relative to now, e.g. "in 12 days" or "3 days ago"
+func relativeTime(t, now time.Time) string {
+	d := t.Sub(now)
+	if d >= 0 {
+		return "in " + humanizeDuration(d)
+	}
+	return humanizeDuration(d) + " ago"
+}
+
+// relativeExpiry describes when a certificate expires relative to now, e.g.
+// "in 12 days" or "expired 3 days ago"
+func relativeExpiry(certInfo cert.CertificateInfo, now time.Time) string {
+	switch {
+	case now.Before(certInfo.NotBefore):
+		return "valid " + relativeTime(certInfo.NotBefore, now)
+	case !now.Before(certInfo.NotAfter):
+		return "expired " + relativeTime(certInfo.NotAfter, now)
+	default:
+		return relativeTime(certInfo.NotAfter, now)
+	}
+}
+
+// pluralize formats a count with a singular or plural unit
+func pluralize(count int, unit string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, unit)
+	}
+	return fmt.Sprintf("%d %ss", count, unit)
+}
@@ -0,0 +1,117 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+const junitSuiteName = "ssl-certs-checker"
+
+// formatJUnit outputs the results as a JUnit XML report, one testcase per host
+func (f *Formatter) formatJUnit(w io.Writer, result *cert.Result) error {
+	report := f.buildJUnitReport(result)
+
+	xmlOutput, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JUnit XML: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w, xml.Header+string(xmlOutput))
+	return err
+}
+
+// buildJUnitReport converts certificate results into a JUnit test report
+func (f *Formatter) buildJUnitReport(result *cert.Result) junitTestSuites {
+	now := f.now()
+	suite := junitTestSuite{
+		Name: junitSuiteName,
+		Time: junitSeconds(result.Duration),
+	}
+	if !result.CheckedAt.IsZero() {
+		suite.Timestamp = result.CheckedAt.UTC().Format("2006-01-02T15:04:05")
+	}
+
+	for _, certInfo := range result.Certificates {
+		testCase := junitTestCase{
+			Name:      certInfo.Host,
+			ClassName: junitSuiteName,
+			Time:      junitSeconds(certInfo.Duration),
+			SystemOut: junitCertificateDetails(certInfo),
+		}
+
+		status, reason := f.options.Thresholds.Evaluate(certInfo, now)
+		switch status {
+		case cert.StatusCritical:
+			testCase.Failure = &junitProblem{
+				Message: reason,
+				Type:    "expiry",
+				Text:    testCase.SystemOut,
+			}
+			suite.Failures++
+		case cert.StatusWarning:
+			testCase.SystemOut = "WARNING: " + reason + "\n" + testCase.SystemOut
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	for _, errInfo := range result.Errors {
+		testCase := junitTestCase{
+			Name:      errInfo.Host,
+			ClassName: junitSuiteName,
+			Time:      junitSeconds(errInfo.Duration),
+		}
+
+		problem := &junitProblem{
+			Message: errInfo.Error,
+			Type:    string(errInfo.Kind),
+			Text:    errInfo.Error,
+		}
+		if errInfo.Kind == cert.ErrorKindVerification {
+			testCase.Failure = problem
+			suite.Failures++
+		} else {
+			testCase.Error = problem
+			suite.Errors++
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	sort.SliceStable(suite.Cases, func(i, j int) bool {
+		return suite.Cases[i].Name < suite.Cases[j].Name
+	})
+	suite.Tests = len(suite.Cases)
+
+	return junitTestSuites{
+		Name:     junitSuiteName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+}
+
+// junitCertificateDetails describes a certificate for the testcase output
+func junitCertificateDetails(certInfo cert.CertificateInfo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Common Name: %s\n", certInfo.CommonName)
+	fmt.Fprintf(&b, "DNS Names: %s\n", strings.Join(certInfo.DNSNames, ", "))
+	fmt.Fprintf(&b, "Not Before: %s\n", certInfo.NotBefore.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Not After: %s\n", certInfo.NotAfter.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Public Key Algorithm: %s\n", certInfo.PublicKeyAlgorithm)
+	fmt.Fprintf(&b, "Issuer: %s\n", certInfo.Issuer)
+	return b.String()
+}
+
+// junitSeconds formats a duration the way JUnit consumers expect
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
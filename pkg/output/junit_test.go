@@ -0,0 +1,117 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+func TestFormatter_BuildJUnitReport(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	formatter := New()
+	formatter.now = func() time.Time { return now }
+
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:      "ok.example.com:443",
+				NotBefore: now.AddDate(0, -1, 0),
+				NotAfter:  now.AddDate(0, 0, 90),
+				Duration:  150 * time.Millisecond,
+			},
+			{
+				Host:      "warning.example.com:443",
+				NotBefore: now.AddDate(0, -1, 0),
+				NotAfter:  now.AddDate(0, 0, 20),
+			},
+			{
+				Host:      "expired.example.com:443",
+				NotBefore: now.AddDate(-1, 0, 0),
+				NotAfter:  now.AddDate(0, 0, -3),
+			},
+		},
+		Errors: []cert.ErrorInfo{
+			{
+				Host:  "selfsigned.example.com:443",
+				Error: "x509: certificate signed by unknown authority",
+				Kind:  cert.ErrorKindVerification,
+			},
+			{
+				Host:  "down.example.com:443",
+				Error: "connection refused",
+				Kind:  cert.ErrorKindConnection,
+			},
+		},
+		CheckedAt: now,
+		Duration:  2 * time.Second,
+	}
+
+	report := formatter.buildJUnitReport(result)
+
+	if report.Tests != 5 {
+		t.Errorf("tests = %d, want 5", report.Tests)
+	}
+	if report.Failures != 2 {
+		t.Errorf("failures = %d, want 2", report.Failures)
+	}
+	if report.Errors != 1 {
+		t.Errorf("errors = %d, want 1", report.Errors)
+	}
+	if report.Time != "2.000" {
+		t.Errorf("time = %s, want 2.000", report.Time)
+	}
+
+	cases := make(map[string]junitTestCase)
+	for _, testCase := range report.Suites[0].Cases {
+		cases[testCase.Name] = testCase
+	}
+
+	if c := cases["ok.example.com:443"]; c.Failure != nil || c.Error != nil || c.Time != "0.150" {
+		t.Errorf("unexpected testcase for healthy host: %+v", c)
+	}
+	if c := cases["warning.example.com:443"]; c.Failure != nil || !strings.HasPrefix(c.SystemOut, "WARNING:") {
+		t.Errorf("warning host should pass with a warning note: %+v", c)
+	}
+	if c := cases["expired.example.com:443"]; c.Failure == nil || !strings.Contains(c.Failure.Message, "expired 3 day(s) ago") {
+		t.Errorf("expired host should fail: %+v", c)
+	}
+	if c := cases["selfsigned.example.com:443"]; c.Failure == nil || c.Failure.Type != "verification" {
+		t.Errorf("verification problem should be a failure: %+v", c)
+	}
+	if c := cases["down.example.com:443"]; c.Error == nil || c.Error.Message != "connection refused" {
+		t.Errorf("connection problem should be an error: %+v", c)
+	}
+}
+
+func TestFormatter_Format_JUnit(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewWithOptions(Options{Thresholds: cert.DefaultThresholds(), Out: &buf})
+
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:       "example.com:443",
+				CommonName: "example.com",
+				NotBefore:  time.Now().AddDate(0, -1, 0),
+				NotAfter:   time.Now().AddDate(0, 3, 0),
+			},
+		},
+	}
+
+	if err := formatter.Format(result, "junit"); err != nil {
+		t.Errorf("Format() unexpected error: %v", err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Format() produced invalid XML: %v", err)
+	}
+
+	if report.Tests != 1 || report.Failures != 0 {
+		t.Errorf("unexpected report counters: tests=%d failures=%d", report.Tests, report.Failures)
+	}
+}
@@ -0,0 +1,158 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// Nagios plugin exit codes
+const (
+	NagiosOK       = 0
+	NagiosWarning  = 1
+	NagiosCritical = 2
+	NagiosUnknown  = 3
+)
+
+var nagiosStatusNames = map[int]string{
+	NagiosOK:       "OK",
+	NagiosWarning:  "WARNING",
+	NagiosCritical: "CRITICAL",
+	NagiosUnknown:  "UNKNOWN",
+}
+
+// nagiosSeverity ranks exit codes so that the worst status wins
+var nagiosSeverity = map[int]int{
+	NagiosOK:       0,
+	NagiosWarning:  1,
+	NagiosUnknown:  2,
+	NagiosCritical: 3,
+}
+
+type nagiosCheck struct {
+	host     string
+	code     int
+	message  string
+	daysLeft *int
+}
+
+// formatNagios outputs the results following the Nagios plugin contract
+func (f *Formatter) formatNagios(w io.Writer, result *cert.Result) error {
+	_, line := f.buildNagiosReport(result)
+
+	_, err := fmt.Fprintln(w, line)
+	return err
+}
+
+// NagiosExitCode returns the Nagios plugin exit code summarizing the results
+func (f *Formatter) NagiosExitCode(result *cert.Result) int {
+	code, _ := f.buildNagiosReport(result)
+	return code
+}
+
+// WriteNagiosUnknown writes the status line reported when the check itself failed
+func WriteNagiosUnknown(w io.Writer, err error) {
+	fmt.Fprintf(w, "SSL UNKNOWN - %s\n", strings.ReplaceAll(err.Error(), "|", "/"))
+}
+
+// buildNagiosReport returns the exit code and the status line with perfdata
+func (f *Formatter) buildNagiosReport(result *cert.Result) (int, string) {
+	checks := f.nagiosChecks(result)
+	if len(checks) == 0 {
+		return NagiosUnknown, "SSL UNKNOWN - no certificates checked"
+	}
+
+	code := NagiosOK
+	counts := make(map[int]int)
+	var problems []string
+	for _, check := range checks {
+		counts[check.code]++
+		if nagiosSeverity[check.code] > nagiosSeverity[code] {
+			code = check.code
+		}
+		if check.code != NagiosOK {
+			problems = append(problems, fmt.Sprintf("%s %s", check.host, check.message))
+		}
+	}
+
+	var summary string
+	switch {
+	case len(checks) == 1:
+		summary = fmt.Sprintf("%s %s", checks[0].host, checks[0].message)
+	case len(problems) == 0:
+		summary = fmt.Sprintf("%d certificate(s) OK", len(checks))
+	default:
+		summary = fmt.Sprintf("%d critical, %d warning, %d unknown, %d ok: %s",
+			counts[NagiosCritical], counts[NagiosWarning], counts[NagiosUnknown], counts[NagiosOK],
+			strings.Join(problems, ", "))
+	}
+
+	// Nagios treats "N:" as "alert when the value drops below N"
+	thresholds := fmt.Sprintf("%d:;%d:", f.options.Thresholds.WarningDays, f.options.Thresholds.CriticalDays)
+
+	perfdata := make([]string, 0, len(checks))
+	for _, check := range checks {
+		label := "days_left"
+		if len(checks) > 1 {
+			label = fmt.Sprintf("'days_left_%s'", check.host)
+		}
+
+		value := "U"
+		if check.daysLeft != nil {
+			value = fmt.Sprintf("%d", *check.daysLeft)
+		}
+
+		perfdata = append(perfdata, fmt.Sprintf("%s=%s;%s", label, value, thresholds))
+	}
+
+	line := fmt.Sprintf("SSL %s - %s | %s", nagiosStatusNames[code], strings.ReplaceAll(summary, "|", "/"), strings.Join(perfdata, " "))
+	return code, line
+}
+
+// nagiosChecks evaluates every host of the result, sorted by host
+func (f *Formatter) nagiosChecks(result *cert.Result) []nagiosCheck {
+	now := f.now()
+	checks := make([]nagiosCheck, 0, len(result.Certificates)+len(result.Errors))
+
+	for _, certInfo := range result.Certificates {
+		status, reason := f.options.Thresholds.Evaluate(certInfo, now)
+		daysLeft := certInfo.DaysLeft(now)
+
+		code := NagiosOK
+		switch status {
+		case cert.StatusWarning:
+			code = NagiosWarning
+		case cert.StatusCritical:
+			code = NagiosCritical
+		}
+
+		checks = append(checks, nagiosCheck{
+			host:     certInfo.Host,
+			code:     code,
+			message:  reason,
+			daysLeft: &daysLeft,
+		})
+	}
+
+	for _, errInfo := range result.Errors {
+		code := NagiosCritical
+		if errInfo.Kind == cert.ErrorKindInvalidHost {
+			code = NagiosUnknown
+		}
+
+		checks = append(checks, nagiosCheck{
+			host:    errInfo.Host,
+			code:    code,
+			message: errInfo.Error,
+		})
+	}
+
+	sort.SliceStable(checks, func(i, j int) bool {
+		return checks[i].host < checks[j].host
+	})
+
+	return checks
+}
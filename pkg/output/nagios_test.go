@@ -0,0 +1,95 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+func TestFormatter_BuildNagiosReport(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	healthy := cert.CertificateInfo{Host: "ok.example.com:443", NotBefore: now.AddDate(0, -1, 0), NotAfter: now.AddDate(0, 0, 60)}
+	warning := cert.CertificateInfo{Host: "warning.example.com:443", NotBefore: now.AddDate(0, -1, 0), NotAfter: now.AddDate(0, 0, 20)}
+	expired := cert.CertificateInfo{Host: "expired.example.com:443", NotBefore: now.AddDate(-1, 0, 0), NotAfter: now.AddDate(0, 0, -2)}
+
+	tests := []struct {
+		name         string
+		result       *cert.Result
+		wantCode     int
+		wantPrefix   string
+		wantPerfdata []string
+	}{
+		{
+			name:         "single healthy host",
+			result:       &cert.Result{Certificates: []cert.CertificateInfo{healthy}},
+			wantCode:     NagiosOK,
+			wantPrefix:   "SSL OK - ok.example.com:443 certificate expires in 60 day(s)",
+			wantPerfdata: []string{"days_left=60;30:;7:"},
+		},
+		{
+			name:         "warning wins over ok",
+			result:       &cert.Result{Certificates: []cert.CertificateInfo{healthy, warning}},
+			wantCode:     NagiosWarning,
+			wantPrefix:   "SSL WARNING - 0 critical, 1 warning, 0 unknown, 1 ok",
+			wantPerfdata: []string{"'days_left_ok.example.com:443'=60;30:;7:", "'days_left_warning.example.com:443'=20;30:;7:"},
+		},
+		{
+			name: "critical wins over everything",
+			result: &cert.Result{
+				Certificates: []cert.CertificateInfo{healthy, expired},
+				Errors:       []cert.ErrorInfo{{Host: "bad host", Error: "invalid host format", Kind: cert.ErrorKindInvalidHost}},
+			},
+			wantCode:     NagiosCritical,
+			wantPrefix:   "SSL CRITICAL - 1 critical, 0 warning, 1 unknown, 1 ok",
+			wantPerfdata: []string{"'days_left_expired.example.com:443'=-2;30:;7:", "'days_left_bad host'=U;30:;7:"},
+		},
+		{
+			name:       "connection errors are critical",
+			result:     &cert.Result{Errors: []cert.ErrorInfo{{Host: "down.example.com:443", Error: "connection refused", Kind: cert.ErrorKindConnection}}},
+			wantCode:   NagiosCritical,
+			wantPrefix: "SSL CRITICAL - down.example.com:443 connection refused",
+		},
+		{
+			name:       "empty result",
+			result:     &cert.Result{},
+			wantCode:   NagiosUnknown,
+			wantPrefix: "SSL UNKNOWN - no certificates checked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter := New()
+			formatter.now = func() time.Time { return now }
+
+			code, line := formatter.buildNagiosReport(tt.result)
+			if code != tt.wantCode {
+				t.Errorf("buildNagiosReport() code = %d, want %d", code, tt.wantCode)
+			}
+			if !strings.HasPrefix(line, tt.wantPrefix) {
+				t.Errorf("buildNagiosReport() line = %q, want prefix %q", line, tt.wantPrefix)
+			}
+			if strings.Contains(line, "\n") {
+				t.Errorf("buildNagiosReport() line should be a single line: %q", line)
+			}
+			for _, perfdata := range tt.wantPerfdata {
+				if !strings.Contains(line, perfdata) {
+					t.Errorf("buildNagiosReport() line = %q, missing perfdata %q", line, perfdata)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteNagiosUnknown(t *testing.T) {
+	var buf bytes.Buffer
+	WriteNagiosUnknown(&buf, errors.New("cannot read a|b"))
+
+	if got := buf.String(); got != "SSL UNKNOWN - cannot read a/b\n" {
+		t.Errorf("WriteNagiosUnknown() = %q", got)
+	}
+}
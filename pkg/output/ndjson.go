@@ -0,0 +1,82 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// formatNDJSON outputs the results as newline delimited JSON
+func (f *Formatter) formatNDJSON(w io.Writer, result *cert.Result) error {
+	stream := f.newNDJSONStream(w)
+
+	for i := range result.Certificates {
+		if err := stream.Write(cert.Outcome{Certificate: &result.Certificates[i]}); err != nil {
+			return err
+		}
+	}
+
+	for i := range result.Errors {
+		if err := stream.Write(cert.Outcome{Error: &result.Errors[i]}); err != nil {
+			return err
+		}
+	}
+
+	for i := range result.Rotations {
+		if err := stream.Write(cert.Outcome{Rotation: &result.Rotations[i]}); err != nil {
+			return err
+		}
+	}
+
+	return stream.Close(result)
+}
+
+// newNDJSONStream creates a stream writing one JSON object per line
+func (f *Formatter) newNDJSONStream(w io.Writer) Stream {
+	return &ndjsonStream{formatter: f, encoder: json.NewEncoder(w)}
+}
+
+// Write writes the outcome of a single host as one line, followed by a
+// rotation line when the certificate was rotated
+func (s *ndjsonStream) Write(outcome cert.Outcome) error {
+	var records []any
+	switch {
+	case outcome.Certificate != nil:
+		records = append(records, ndjsonCertificate{Type: "certificate", CertificateInfo: *outcome.Certificate})
+	case outcome.Error != nil:
+		records = append(records, ndjsonError{Type: "error", ErrorInfo: *outcome.Error})
+	}
+
+	if outcome.Rotation != nil {
+		records = append(records, ndjsonRotation{Type: "rotation", Rotation: *outcome.Rotation})
+	}
+
+	for _, record := range records {
+		if err := s.encoder.Encode(record); err != nil {
+			return fmt.Errorf("error marshaling NDJSON: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close writes a final summary line holding the statistics of the run
+func (s *ndjsonStream) Close(result *cert.Result) error {
+	summary := ndjsonSummary{
+		Type:         "summary",
+		Certificates: len(result.Certificates),
+		ErrorLines:   len(result.Errors),
+		Rotations:    len(result.Rotations),
+		CheckedAt:    result.CheckedAt,
+		Duration:     result.Duration,
+		Summary:      s.formatter.Summarize(result),
+
+		ConfigVersion: result.ConfigVersion,
+	}
+
+	if err := s.encoder.Encode(summary); err != nil {
+		return fmt.Errorf("error marshaling NDJSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// decodeLines decodes every line of NDJSON output
+func decodeLines(t *testing.T, data []byte) []map[string]any {
+	t.Helper()
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestFormatter_NewStream_NDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewWithOptions(Options{Out: &buf})
+
+	stream, err := formatter.NewStream("ndjson")
+	if err != nil {
+		t.Fatalf("NewStream() unexpected error: %v", err)
+	}
+
+	certInfo := cert.CertificateInfo{Host: "example.com:443", CommonName: "example.com"}
+	if err := stream.Write(cert.Outcome{Certificate: &certInfo}); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+
+	// Every outcome is written immediately
+	if lines := decodeLines(t, buf.Bytes()); len(lines) != 1 || lines[0]["type"] != "certificate" || lines[0]["host"] != "example.com:443" {
+		t.Fatalf("Write() should write the certificate line immediately, got %q", buf.String())
+	}
+
+	errInfo := cert.ErrorInfo{Host: "down.example.com:443", Error: "connection refused"}
+	if err := stream.Write(cert.Outcome{Error: &errInfo}); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{certInfo},
+		Errors:       []cert.ErrorInfo{errInfo},
+	}
+	if err := stream.Close(result); err != nil {
+		t.Fatalf("Close() unexpected error: %v", err)
+	}
+
+	lines := decodeLines(t, buf.Bytes())
+	if len(lines) != 3 {
+		t.Fatalf("stream wrote %d line(s), want 3", len(lines))
+	}
+	if lines[1]["type"] != "error" || lines[1]["error"] != "connection refused" {
+		t.Errorf("unexpected error line: %v", lines[1])
+	}
+	if lines[2]["type"] != "summary" || lines[2]["certificates"] != float64(1) || lines[2]["error_lines"] != float64(1) {
+		t.Errorf("unexpected summary line: %v", lines[2])
+	}
+	if lines[2]["total"] != float64(2) || lines[2]["errors"] != float64(1) || lines[2]["issuers"] == nil {
+		t.Errorf("summary line should hold the statistics: %v", lines[2])
+	}
+}
+
+func TestFormatter_NewStream_NotStreaming(t *testing.T) {
+	if _, err := New().NewStream("table"); err == nil {
+		t.Error("NewStream() should fail for formats without streaming support")
+	}
+}
+
+func TestFormatter_FormatTo_NDJSON(t *testing.T) {
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{{Host: "a.example.com:443"}, {Host: "b.example.com:443"}},
+		Errors:       []cert.ErrorInfo{{Host: "c.example.com:443", Error: "timeout"}},
+	}
+
+	var buf bytes.Buffer
+	if err := New().FormatTo(&buf, result, "ndjson"); err != nil {
+		t.Fatalf("FormatTo() unexpected error: %v", err)
+	}
+
+	lines := decodeLines(t, buf.Bytes())
+	if len(lines) != 4 {
+		t.Fatalf("FormatTo() wrote %d line(s), want 4", len(lines))
+	}
+	if lines[3]["type"] != "summary" {
+		t.Errorf("FormatTo() should end with a summary line, got %v", lines[3])
+	}
+}
+
+func TestFormatter_Format_NDJSONRotations(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewWithOptions(Options{Out: &buf})
+
+	rotation := cert.Rotation{
+		Host: "example.com:443",
+		Old:  cert.CertificateVersion{SerialNumber: "01"},
+		New:  cert.CertificateVersion{SerialNumber: "02"},
+	}
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{{Host: "example.com:443", SerialNumber: "02"}},
+		Rotations:    []cert.Rotation{rotation},
+	}
+
+	if err := formatter.Format(result, "ndjson"); err != nil {
+		t.Fatalf("Format() unexpected error: %v", err)
+	}
+
+	lines := decodeLines(t, buf.Bytes())
+	if len(lines) != 3 || lines[1]["type"] != "rotation" || lines[2]["rotations"] != float64(1) {
+		t.Fatalf("Format() should write a rotation line, got %q", buf.String())
+	}
+	if old, ok := lines[1]["old"].(map[string]any); !ok || old["serial_number"] != "01" {
+		t.Errorf("rotation line should hold the old certificate: %v", lines[1])
+	}
+
+	// Streams write the rotation right after the certificate
+	buf.Reset()
+	stream, err := formatter.NewStream("ndjson")
+	if err != nil {
+		t.Fatalf("NewStream() unexpected error: %v", err)
+	}
+	if err := stream.Write(cert.Outcome{Certificate: &result.Certificates[0], Rotation: &rotation}); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+	if lines := decodeLines(t, buf.Bytes()); len(lines) != 2 || lines[0]["type"] != "certificate" || lines[1]["type"] != "rotation" {
+		t.Errorf("Write() = %q, want a certificate and a rotation line", buf.String())
+	}
+}
@@ -0,0 +1,39 @@
+package output
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+type ndjsonStream struct {
+	formatter *Formatter
+	encoder   *json.Encoder
+}
+
+type ndjsonCertificate struct {
+	Type string `json:"type"`
+	cert.CertificateInfo
+}
+
+type ndjsonError struct {
+	Type string `json:"type"`
+	cert.ErrorInfo
+}
+
+type ndjsonRotation struct {
+	Type string `json:"type"`
+	cert.Rotation
+}
+
+type ndjsonSummary struct {
+	Type         string        `json:"type"`
+	Certificates int           `json:"certificates"`
+	ErrorLines   int           `json:"error_lines"`
+	Rotations    int           `json:"rotations"`
+	CheckedAt    time.Time     `json:"checked_at"`
+	Duration     time.Duration `json:"duration"`
+	cert.Summary
+	ConfigVersion string `json:"config_version,omitempty"`
+}
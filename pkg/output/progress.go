@@ -0,0 +1,198 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+const (
+	progressInterval     = 100 * time.Millisecond
+	defaultTerminalWidth = 80
+	maxInFlightShown     = 3
+)
+
+// IsTerminal reports whether w is a terminal
+func IsTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// NewProgress creates a live progress line for checking total hosts, meant
+// to be drawn on a terminal
+func NewProgress(w io.Writer, total int) *Progress {
+	width := defaultTerminalWidth
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		width = columns
+	}
+
+	return &Progress{
+		w:        w,
+		total:    total,
+		width:    width,
+		now:      time.Now,
+		inFlight: make(map[string]time.Time),
+	}
+}
+
+// Start begins redrawing the progress line periodically
+func (p *Progress) Start() {
+	p.mutex.Lock()
+	p.startedAt = p.now()
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	p.mutex.Unlock()
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.draw()
+			}
+		}
+	}()
+}
+
+// Stop stops redrawing and clears the progress line
+func (p *Progress) Stop() {
+	if p.stop == nil {
+		return
+	}
+
+	close(p.stop)
+	<-p.done
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.drawn {
+		fmt.Fprint(p.w, "\r\033[K")
+		p.drawn = false
+	}
+}
+
+// Started records that the check of host started
+func (p *Progress) Started(host string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.inFlight[host] = p.now()
+}
+
+// Completed records the outcome of a host check
+func (p *Progress) Completed(outcome cert.Outcome) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.completed++
+
+	switch {
+	case outcome.Error != nil:
+		p.failed++
+		delete(p.inFlight, outcome.Error.Host)
+	case outcome.Certificate != nil:
+		delete(p.inFlight, outcome.Certificate.Host)
+	}
+}
+
+// draw redraws the progress line in place
+func (p *Progress) draw() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	line := p.line()
+	if p.width > 1 {
+		line = truncateWidth(line, p.width-1)
+	}
+
+	fmt.Fprint(p.w, "\r\033[K"+line)
+	p.drawn = true
+}
+
+// truncateWidth cuts line to at most width terminal columns, so that wide
+// characters of host names never wrap the progress line
+func truncateWidth(line string, width int) string {
+	used := 0
+	for i, r := range line {
+		used += text.RuneWidth(r)
+		if used > width {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// line describes the current progress, the caller must hold the mutex
+func (p *Progress) line() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Checking %d/%d host(s)", p.completed, p.total)
+
+	if p.failed > 0 {
+		fmt.Fprintf(&b, ", %d failed", p.failed)
+	}
+
+	if len(p.inFlight) > 0 {
+		hosts := make([]string, 0, len(p.inFlight))
+		for host := range p.inFlight {
+			hosts = append(hosts, host)
+		}
+		sort.Slice(hosts, func(i, j int) bool {
+			if started := p.inFlight[hosts[i]]; !started.Equal(p.inFlight[hosts[j]]) {
+				return started.Before(p.inFlight[hosts[j]])
+			}
+			return hosts[i] < hosts[j]
+		})
+
+		shown := hosts
+		if len(shown) > maxInFlightShown {
+			shown = shown[:maxInFlightShown]
+		}
+
+		fmt.Fprintf(&b, ", %d in flight: %s", len(hosts), strings.Join(shown, ", "))
+		if len(hosts) > len(shown) {
+			fmt.Fprintf(&b, ", +%d more", len(hosts)-len(shown))
+		}
+	}
+
+	fmt.Fprintf(&b, " | ETA %s", p.eta())
+	return b.String()
+}
+
+// eta estimates the remaining time from the average pace so far
+func (p *Progress) eta() string {
+	if p.completed == 0 {
+		return "--"
+	}
+
+	remaining := p.total - p.completed
+	if remaining <= 0 {
+		return "0s"
+	}
+
+	elapsed := p.now().Sub(p.startedAt)
+	estimate := elapsed / time.Duration(p.completed) * time.Duration(remaining)
+	return estimate.Round(time.Second).String()
+}
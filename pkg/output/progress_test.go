@@ -0,0 +1,101 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+func TestIsTerminal(t *testing.T) {
+	if IsTerminal(&bytes.Buffer{}) {
+		t.Error("IsTerminal() should be false for buffers")
+	}
+
+	file, err := os.CreateTemp(t.TempDir(), "progress")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer file.Close()
+
+	if IsTerminal(file) {
+		t.Error("IsTerminal() should be false for regular files")
+	}
+}
+
+func TestProgress_Line(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	progress := NewProgress(&bytes.Buffer{}, 10)
+	progress.now = func() time.Time { return now }
+	progress.startedAt = now
+
+	if line := progress.line(); line != "Checking 0/10 host(s) | ETA --" {
+		t.Errorf("line() = %q", line)
+	}
+
+	for _, host := range []string{"a:443", "b:443", "c:443", "d:443", "e:443"} {
+		now = now.Add(time.Second)
+		progress.Started(host)
+	}
+
+	progress.Completed(cert.Outcome{Certificate: &cert.CertificateInfo{Host: "a:443"}})
+	progress.Completed(cert.Outcome{Error: &cert.ErrorInfo{Host: "b:443"}})
+
+	// 2 hosts in 5 seconds, 8 remaining
+	want := "Checking 2/10 host(s), 1 failed, 3 in flight: c:443, d:443, e:443 | ETA 20s"
+	if line := progress.line(); line != want {
+		t.Errorf("line() = %q, want %q", line, want)
+	}
+
+	now = now.Add(time.Second)
+	progress.Started("f:443")
+	if line := progress.line(); !strings.Contains(line, "4 in flight: c:443, d:443, e:443, +1 more") {
+		t.Errorf("line() = %q, should truncate the in-flight hosts", line)
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		line  string
+		width int
+		want  string
+	}{
+		{line: "example.com", width: 20, want: "example.com"},
+		{line: "example.com", width: 7, want: "example"},
+		{line: "bücher.example", width: 6, want: "bücher"},
+		{line: "例え.テスト", width: 5, want: "例え."},
+		{line: "例え.テスト", width: 4, want: "例え"},
+		{line: "例え.テスト", width: 3, want: "例"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateWidth(tt.line, tt.width); got != tt.want {
+			t.Errorf("truncateWidth(%q, %d) = %q, want %q", tt.line, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestProgress_StartStop(t *testing.T) {
+	var buf bytes.Buffer
+
+	progress := NewProgress(&buf, 1)
+	progress.Start()
+	progress.Started("example.com:443")
+	time.Sleep(3 * progressInterval)
+	progress.Stop()
+
+	output := buf.String()
+	if !strings.Contains(output, "Checking 0/1 host(s), 1 in flight: example.com:443") {
+		t.Errorf("progress output = %q, should describe the running check", output)
+	}
+	if !strings.HasSuffix(output, "\r\033[K") {
+		t.Errorf("progress output = %q, should end by clearing the line", output)
+	}
+
+	// Stopping a progress that never started is a no-op
+	NewProgress(&buf, 1).Stop()
+}
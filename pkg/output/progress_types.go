@@ -0,0 +1,24 @@
+package output
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+type Progress struct {
+	w     io.Writer
+	total int
+	width int
+	now   func() time.Time
+
+	mutex     sync.Mutex
+	startedAt time.Time
+	completed int
+	failed    int
+	inFlight  map[string]time.Time
+	drawn     bool
+
+	stop chan struct{}
+	done chan struct{}
+}
@@ -0,0 +1,137 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+const TextfileName = "ssl_certs_checker.prom"
+
+// formatPrometheus outputs the results in the Prometheus text exposition format
+func (f *Formatter) formatPrometheus(w io.Writer, result *cert.Result) error {
+	return WritePrometheus(w, result, f.now())
+}
+
+// WritePrometheus writes the results in the Prometheus text exposition format
+func WritePrometheus(w io.Writer, result *cert.Result, now time.Time) error {
+	certificates := append([]cert.CertificateInfo(nil), result.Certificates...)
+	sort.SliceStable(certificates, func(i, j int) bool {
+		return certificates[i].Host < certificates[j].Host
+	})
+
+	errors := append([]cert.ErrorInfo(nil), result.Errors...)
+	sort.SliceStable(errors, func(i, j int) bool {
+		return errors[i].Host < errors[j].Host
+	})
+
+	bw := bufio.NewWriter(w)
+
+	writeMetricHeader(bw, "ssl_cert_not_after_timestamp_seconds", "Unix time after which the certificate is no longer valid.")
+	for _, certInfo := range certificates {
+		writeSample(bw, "ssl_cert_not_after_timestamp_seconds", certificateLabels(certInfo), float64(certInfo.NotAfter.Unix()))
+	}
+
+	writeMetricHeader(bw, "ssl_cert_not_before_timestamp_seconds", "Unix time before which the certificate is not valid.")
+	for _, certInfo := range certificates {
+		writeSample(bw, "ssl_cert_not_before_timestamp_seconds", certificateLabels(certInfo), float64(certInfo.NotBefore.Unix()))
+	}
+
+	writeMetricHeader(bw, "ssl_cert_days_left", "Number of whole days until the certificate expires.")
+	for _, certInfo := range certificates {
+		writeSample(bw, "ssl_cert_days_left", certificateLabels(certInfo), float64(certInfo.DaysLeft(now)))
+	}
+
+	writeMetricHeader(bw, "ssl_cert_check_success", "Whether the certificate could be retrieved (1) or not (0).")
+	for _, certInfo := range certificates {
+		writeSample(bw, "ssl_cert_check_success", hostLabels(certInfo.Host), 1)
+	}
+	for _, errInfo := range errors {
+		writeSample(bw, "ssl_cert_check_success", hostLabels(errInfo.Host), 0)
+	}
+
+	writeMetricHeader(bw, "ssl_cert_check_duration_seconds", "Time spent retrieving the certificate.")
+	for _, certInfo := range certificates {
+		writeSample(bw, "ssl_cert_check_duration_seconds", hostLabels(certInfo.Host), certInfo.Duration.Seconds())
+	}
+	for _, errInfo := range errors {
+		writeSample(bw, "ssl_cert_check_duration_seconds", hostLabels(errInfo.Host), errInfo.Duration.Seconds())
+	}
+
+	if !result.CheckedAt.IsZero() {
+		writeMetricHeader(bw, "ssl_cert_last_check_timestamp_seconds", "Unix time at which the last check started.")
+		writeSample(bw, "ssl_cert_last_check_timestamp_seconds", nil, float64(result.CheckedAt.Unix()))
+	}
+
+	if result.ConfigVersion != "" {
+		writeMetricHeader(bw, "ssl_cert_config_info", "Version of the loaded config file, always 1.")
+		writeSample(bw, "ssl_cert_config_info", [][2]string{{"version", result.ConfigVersion}}, 1)
+	}
+
+	return bw.Flush()
+}
+
+// WriteTextfile atomically writes the Prometheus metrics to a node_exporter
+// textfile collector file, path may either be a file or a directory
+func (f *Formatter) WriteTextfile(path string, result *cert.Result) error {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, TextfileName)
+	}
+
+	return f.WriteFile(path, result, "prometheus")
+}
+
+// writeMetricHeader writes the HELP and TYPE lines of a gauge
+func writeMetricHeader(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+}
+
+// writeSample writes a single sample line
+func writeSample(w io.Writer, name string, labels [][2]string, value float64) {
+	if len(labels) == 0 {
+		fmt.Fprintf(w, "%s %s\n", name, formatSampleValue(value))
+		return
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for _, label := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", label[0], escapeLabelValue(label[1])))
+	}
+	fmt.Fprintf(w, "%s{%s} %s\n", name, strings.Join(pairs, ","), formatSampleValue(value))
+}
+
+// formatSampleValue formats a sample value without exponent notation
+func formatSampleValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// certificateLabels returns the labels identifying a certificate
+func certificateLabels(certInfo cert.CertificateInfo) [][2]string {
+	return [][2]string{
+		{"host", certInfo.Host},
+		{"cn", certInfo.CommonName},
+		{"issuer", certInfo.Issuer},
+		{"serial", certInfo.SerialNumber},
+	}
+}
+
+// hostLabels returns the labels identifying a checked host
+func hostLabels(host string) [][2]string {
+	return [][2]string{{"host", host}}
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue escapes a label value for the exposition format
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
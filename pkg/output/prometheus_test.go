@@ -0,0 +1,100 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+func prometheusTestResult(now time.Time) *cert.Result {
+	return &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:         "example.com:443",
+				CommonName:   "example.com",
+				Issuer:       `Test "Quoted" CA`,
+				SerialNumber: "0A1B",
+				NotBefore:    now.AddDate(0, -1, 0),
+				NotAfter:     now.AddDate(0, 0, 42),
+				Duration:     250 * time.Millisecond,
+			},
+		},
+		Errors: []cert.ErrorInfo{
+			{
+				Host:     "down.example.com:443",
+				Error:    "connection refused",
+				Duration: time.Second,
+			},
+		},
+		CheckedAt:     now,
+		ConfigVersion: "3f2a1b4c5d6e",
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, prometheusTestResult(now), now); err != nil {
+		t.Fatalf("WritePrometheus() unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	labels := `host="example.com:443",cn="example.com",issuer="Test \"Quoted\" CA",serial="0A1B"`
+
+	expected := []string{
+		"# TYPE ssl_cert_not_after_timestamp_seconds gauge",
+		"ssl_cert_not_after_timestamp_seconds{" + labels + "} " + strconv.FormatInt(now.AddDate(0, 0, 42).Unix(), 10),
+		"ssl_cert_days_left{" + labels + "} 42",
+		`ssl_cert_check_success{host="example.com:443"} 1`,
+		`ssl_cert_check_success{host="down.example.com:443"} 0`,
+		`ssl_cert_check_duration_seconds{host="example.com:443"} 0.25`,
+		`ssl_cert_check_duration_seconds{host="down.example.com:443"} 1`,
+		"ssl_cert_last_check_timestamp_seconds " + strconv.FormatInt(now.Unix(), 10),
+		`ssl_cert_config_info{version="3f2a1b4c5d6e"} 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("WritePrometheus() output missing line %q\n%s", line, output)
+		}
+	}
+}
+
+func TestFormatter_WriteTextfile(t *testing.T) {
+	tempDir := t.TempDir()
+	formatter := New()
+	result := prometheusTestResult(time.Now())
+
+	// Writing to a directory uses the default file name
+	if err := formatter.WriteTextfile(tempDir, result); err != nil {
+		t.Fatalf("WriteTextfile() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tempDir, TextfileName))
+	if err != nil {
+		t.Fatalf("WriteTextfile() did not create the textfile: %v", err)
+	}
+	if !strings.Contains(string(data), "ssl_cert_days_left") {
+		t.Error("WriteTextfile() should write Prometheus metrics")
+	}
+
+	// Writing to an explicit file path
+	customPath := filepath.Join(tempDir, "custom.prom")
+	if err := formatter.WriteTextfile(customPath, result); err != nil {
+		t.Fatalf("WriteTextfile() unexpected error: %v", err)
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to read temp dir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("WriteTextfile() left unexpected files behind: %v", entries)
+	}
+}
@@ -0,0 +1,248 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+const DefaultFormat = "table"
+
+var defaultRegistry = newBuiltinRegistry()
+
+// NewRegistry creates an empty format registry
+func NewRegistry() *Registry {
+	return &Registry{
+		formats: make(map[string]Format),
+	}
+}
+
+// Register adds a format to the registry, names must be unique
+func (r *Registry) Register(format Format) error {
+	if format.Name == "" {
+		return fmt.Errorf("format name cannot be empty")
+	}
+
+	if format.Render == nil {
+		return fmt.Errorf("format %s has no render function", format.Name)
+	}
+
+	if format.Capabilities.Streaming && format.NewStream == nil {
+		return fmt.Errorf("streaming format %s has no stream function", format.Name)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.formats[format.Name]; exists {
+		return fmt.Errorf("format %s is already registered", format.Name)
+	}
+
+	r.formats[format.Name] = format
+	r.order = append(r.order, format.Name)
+	return nil
+}
+
+// Lookup returns the format registered under name
+func (r *Registry) Lookup(name string) (Format, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	format, ok := r.formats[name]
+	return format, ok
+}
+
+// LookupExtension returns the format implied by a file extension
+func (r *Registry) LookupExtension(ext string) (Format, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, name := range r.order {
+		for _, candidate := range r.formats[name].Extensions {
+			if strings.EqualFold(candidate, ext) {
+				return r.formats[name], true
+			}
+		}
+	}
+
+	return Format{}, false
+}
+
+// Formats returns the registered formats in registration order
+func (r *Registry) Formats() []Format {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	formats := make([]Format, 0, len(r.order))
+	for _, name := range r.order {
+		formats = append(formats, r.formats[name])
+	}
+	return formats
+}
+
+// Names returns the names of the registered formats in registration order
+func (r *Registry) Names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return append([]string(nil), r.order...)
+}
+
+// Check returns an error when no format is registered under name
+func (r *Registry) Check(name string) error {
+	if _, ok := r.Lookup(name); !ok {
+		return fmt.Errorf("invalid output format: %s (supported: %s)", name, strings.Join(r.Names(), ", "))
+	}
+	return nil
+}
+
+// ParseTarget parses an output file specification of the form
+// [format=]path, the format defaults to the one implied by the file
+// extension and then to defaultFormat
+func (r *Registry) ParseTarget(spec, defaultFormat string) (Target, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return Target{}, fmt.Errorf("output file cannot be empty")
+	}
+
+	if format, path, found := strings.Cut(spec, "="); found && r.Check(format) == nil {
+		if path == "" {
+			return Target{}, fmt.Errorf("output file path cannot be empty: %s", spec)
+		}
+		return Target{Format: format, Path: path}, nil
+	}
+
+	format := defaultFormat
+	if implied, ok := r.LookupExtension(filepath.Ext(spec)); ok {
+		format = implied.Name
+	}
+	if format == "" {
+		format = DefaultFormat
+	}
+
+	if err := r.Check(format); err != nil {
+		return Target{}, err
+	}
+
+	return Target{Format: format, Path: spec}, nil
+}
+
+// Register adds a format to the default registry
+func Register(format Format) error {
+	return defaultRegistry.Register(format)
+}
+
+// Lookup returns the format registered under name in the default registry
+func Lookup(name string) (Format, bool) {
+	return defaultRegistry.Lookup(name)
+}
+
+// LookupExtension returns the format implied by a file extension in the default registry
+func LookupExtension(ext string) (Format, bool) {
+	return defaultRegistry.LookupExtension(ext)
+}
+
+// Check returns an error when no format is registered under name in the
+// default registry
+func Check(name string) error {
+	return defaultRegistry.Check(name)
+}
+
+// Formats returns the formats of the default registry
+func Formats() []Format {
+	return defaultRegistry.Formats()
+}
+
+// Names returns the format names of the default registry
+func Names() []string {
+	return defaultRegistry.Names()
+}
+
+// newBuiltinRegistry creates a registry holding the builtin formats
+func newBuiltinRegistry() *Registry {
+	registry := NewRegistry()
+
+	builtins := []Format{
+		{
+			Name:        "table",
+			Description: "human readable table",
+			Render:      (*Formatter).formatTable,
+		},
+		{
+			Name:         "json",
+			Description:  "JSON document",
+			Capabilities: Capabilities{SupportsErrors: true},
+			Extensions:   []string{".json"},
+			Render:       (*Formatter).formatJSON,
+		},
+		{
+			Name:         "yaml",
+			Description:  "YAML document",
+			Capabilities: Capabilities{SupportsErrors: true},
+			Extensions:   []string{".yaml", ".yml"},
+			Render:       (*Formatter).formatYAML,
+		},
+		{
+			Name:         "ndjson",
+			Description:  "newline delimited JSON, one object per host written as soon as it is checked",
+			Capabilities: Capabilities{Streaming: true, SupportsErrors: true},
+			Extensions:   []string{".ndjson", ".jsonl"},
+			Render:       (*Formatter).formatNDJSON,
+			NewStream:    (*Formatter).newNDJSONStream,
+		},
+		{
+			Name:         "junit",
+			Description:  "JUnit XML report, one testcase per host",
+			Capabilities: Capabilities{SupportsErrors: true},
+			Extensions:   []string{".xml"},
+			Render:       (*Formatter).formatJUnit,
+		},
+		{
+			Name:         "prometheus",
+			Description:  "Prometheus text exposition format",
+			Capabilities: Capabilities{SupportsErrors: true},
+			Extensions:   []string{".prom"},
+			Render:       (*Formatter).formatPrometheus,
+		},
+		{
+			Name:         "nagios",
+			Description:  "Nagios/Icinga plugin status line with perfdata",
+			Capabilities: Capabilities{SupportsErrors: true},
+			Render:       (*Formatter).formatNagios,
+		},
+		{
+			Name:         "template",
+			Description:  "custom output rendered from a Go text/template",
+			Capabilities: Capabilities{SupportsErrors: true},
+			Render:       (*Formatter).formatTemplate,
+		},
+	}
+
+	for _, format := range builtins {
+		if err := registry.Register(format); err != nil {
+			panic(err)
+		}
+	}
+
+	return registry
+}
+
+// writeErrors reports check errors for formats that cannot render them
+func writeErrors(w io.Writer, errors []cert.ErrorInfo) {
+	if len(errors) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\nErrors encountered:\n")
+	for _, errInfo := range errors {
+		if errInfo.Source != "" {
+			fmt.Fprintf(w, "  %s (%s): %s\n", errInfo.Host, errInfo.Source, errInfo.Error)
+		} else {
+			fmt.Fprintf(w, "  %s: %s\n", errInfo.Host, errInfo.Error)
+		}
+	}
+	fmt.Fprintf(w, "\n")
+}
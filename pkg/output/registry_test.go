@@ -0,0 +1,192 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+func countFormat(name string, capabilities Capabilities) Format {
+	return Format{
+		Name:         name,
+		Description:  "number of certificates",
+		Capabilities: capabilities,
+		Extensions:   []string{".count"},
+		Render: func(f *Formatter, w io.Writer, result *cert.Result) error {
+			_, err := fmt.Fprintf(w, "%d\n", len(result.Certificates))
+			return err
+		},
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry()
+
+	if err := registry.Register(countFormat("count", Capabilities{})); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+
+	if err := registry.Register(countFormat("count", Capabilities{})); err == nil {
+		t.Error("Register() should reject duplicate names")
+	}
+
+	if err := registry.Register(countFormat("", Capabilities{})); err == nil {
+		t.Error("Register() should reject empty names")
+	}
+
+	if err := registry.Register(Format{Name: "broken"}); err == nil {
+		t.Error("Register() should reject formats without render function")
+	}
+
+	if err := registry.Register(countFormat("stream", Capabilities{Streaming: true})); err == nil {
+		t.Error("Register() should reject streaming formats without stream function")
+	}
+
+	if _, ok := registry.Lookup("count"); !ok {
+		t.Error("Lookup() should find the registered format")
+	}
+
+	if format, ok := registry.LookupExtension(".COUNT"); !ok || format.Name != "count" {
+		t.Errorf("LookupExtension() = %v, %v, want count", format.Name, ok)
+	}
+
+	if names := registry.Names(); len(names) != 1 || names[0] != "count" {
+		t.Errorf("Names() = %v, want [count]", names)
+	}
+}
+
+func TestDefaultRegistry_Builtins(t *testing.T) {
+	for _, name := range []string{"table", "json", "yaml", "ndjson", "junit", "prometheus", "nagios", "template"} {
+		format, ok := Lookup(name)
+		if !ok {
+			t.Errorf("Lookup(%q) should find a builtin format", name)
+			continue
+		}
+		if format.Description == "" {
+			t.Errorf("builtin format %q has no description", name)
+		}
+	}
+
+	// The HTML report is only the body of email reports
+	if _, ok := Lookup("html"); ok {
+		t.Error("Lookup(\"html\") should not find a builtin format")
+	}
+
+	if names := Names(); names[0] != DefaultFormat {
+		t.Errorf("Names() should list the default format first, got %v", names)
+	}
+
+	if format, ok := LookupExtension(".yml"); !ok || format.Name != "yaml" {
+		t.Errorf("LookupExtension(.yml) = %v, %v, want yaml", format.Name, ok)
+	}
+}
+
+func TestFormatter_Format_CustomRegistry(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register(countFormat("count", Capabilities{})); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+
+	var out, diag bytes.Buffer
+	formatter := NewWithOptions(Options{Out: &out, Err: &diag, Registry: registry})
+
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{{Host: "example.com:443"}},
+		Errors:       []cert.ErrorInfo{{Host: "invalid.com:443", Error: "connection failed"}},
+	}
+
+	if err := formatter.Format(result, "count"); err != nil {
+		t.Fatalf("Format() unexpected error: %v", err)
+	}
+	if out.String() != "1\n" {
+		t.Errorf("Format() = %q, want %q", out.String(), "1\n")
+	}
+
+	// Formats without error support get errors on the diagnostics writer
+	if !bytes.Contains(diag.Bytes(), []byte("invalid.com:443: connection failed")) {
+		t.Errorf("Format() should report errors on diagnostics writer, got %q", diag.String())
+	}
+
+	// Builtins are not part of a custom registry
+	if err := formatter.Format(result, "json"); err == nil {
+		t.Error("Format() should only use formats of the configured registry")
+	}
+}
+
+func TestFormatter_Format_SupportsErrors(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register(countFormat("count", Capabilities{SupportsErrors: true})); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+
+	var out, diag bytes.Buffer
+	formatter := NewWithOptions(Options{Out: &out, Err: &diag, Registry: registry})
+
+	result := &cert.Result{
+		Errors: []cert.ErrorInfo{{Host: "invalid.com:443", Error: "connection failed"}},
+	}
+
+	if err := formatter.Format(result, "count"); err != nil {
+		t.Fatalf("Format() unexpected error: %v", err)
+	}
+	if diag.Len() != 0 {
+		t.Errorf("Format() should leave errors to the format, got %q", diag.String())
+	}
+}
+
+func TestRegistry_ParseTarget(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          string
+		defaultFormat string
+		want          Target
+		wantErr       bool
+	}{
+		{name: "explicit format", spec: "yaml=out/report.txt", defaultFormat: "table", want: Target{Format: "yaml", Path: "out/report.txt"}},
+		{name: "json extension", spec: "report.json", defaultFormat: "table", want: Target{Format: "json", Path: "report.json"}},
+		{name: "yml extension", spec: "report.YML", defaultFormat: "table", want: Target{Format: "yaml", Path: "report.YML"}},
+		{name: "xml extension", spec: "junit.xml", defaultFormat: "table", want: Target{Format: "junit", Path: "junit.xml"}},
+		{name: "prom extension", spec: "/var/lib/node_exporter/certs.prom", defaultFormat: "table", want: Target{Format: "prometheus", Path: "/var/lib/node_exporter/certs.prom"}},
+		{name: "unknown extension uses default format", spec: "report.txt", defaultFormat: "nagios", want: Target{Format: "nagios", Path: "report.txt"}},
+		{name: "no default format", spec: "report.txt", want: Target{Format: "table", Path: "report.txt"}},
+		{name: "equals sign in path", spec: "dir=x/report.json", defaultFormat: "table", want: Target{Format: "json", Path: "dir=x/report.json"}},
+		{name: "empty spec", spec: " ", wantErr: true},
+		{name: "empty path", spec: "json=", wantErr: true},
+		{name: "unknown default format", spec: "report.txt", defaultFormat: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := defaultRegistry.ParseTarget(tt.spec, tt.defaultFormat)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseTarget() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTarget() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTarget() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_ParseTarget_CustomRegistry(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register(countFormat("count", Capabilities{})); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+
+	if target, err := registry.ParseTarget("hosts.count", "json"); err != nil || target.Format != "count" {
+		t.Errorf("ParseTarget() = %+v, %v, want the format of the extension", target, err)
+	}
+	if err := registry.Check("json"); err == nil {
+		t.Error("Check() should reject formats of other registries")
+	}
+}
@@ -0,0 +1,55 @@
+package output
+
+import (
+	"io"
+	"sync"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// RenderFunc writes the certificate results in a specific format to w
+type RenderFunc func(f *Formatter, w io.Writer, result *cert.Result) error
+
+// StreamFunc creates a stream writing results to w as the checks complete
+type StreamFunc func(f *Formatter, w io.Writer) Stream
+
+// Stream receives the outcome of every host as soon as it is checked
+type Stream interface {
+	// Write renders the outcome of a single host
+	Write(outcome cert.Outcome) error
+	// Close completes the output once every host has been checked
+	Close(result *cert.Result) error
+}
+
+type Capabilities struct {
+	// Streaming formats can write results as the checks complete
+	Streaming bool
+	// SupportsErrors formats render check errors themselves, otherwise the
+	// formatter reports them on the diagnostics writer
+	SupportsErrors bool
+	// Binary formats produce output that is not meant for terminals
+	Binary bool
+}
+
+type Format struct {
+	Name         string
+	Description  string
+	Capabilities Capabilities
+	// Extensions lists the file extensions implying this format, e.g. ".json"
+	Extensions []string
+	Render     RenderFunc
+	// NewStream is required for formats with the Streaming capability
+	NewStream StreamFunc
+}
+
+type Registry struct {
+	mutex   sync.RWMutex
+	formats map[string]Format
+	order   []string
+}
+
+// Target is an additional file the results are written to in a format
+type Target struct {
+	Format string
+	Path   string
+}
@@ -0,0 +1,262 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+const (
+	DefaultTimeFormat = "2006-01-02 15:04:05 MST"
+	compactDNSNames   = 3
+)
+
+var statusColors = map[cert.Status]text.Colors{
+	cert.StatusWarning:  {text.FgYellow},
+	cert.StatusCritical: {text.FgRed},
+}
+
+// formatTable outputs the results in table format
+func (f *Formatter) formatTable(w io.Writer, result *cert.Result) error {
+	now := f.now()
+
+	header := table.Row{
+		"Host",
+		"Common Name",
+		"DNS Names",
+		"Not Before",
+		"Not After",
+		"Days Left",
+		"Expires",
+		"PublicKeyAlgorithm",
+		"Issuer",
+	}
+
+	certificates := result.Certificates
+	group := f.options.GroupBy
+	if group != "" {
+		header = append(table.Row{strings.ToUpper(group[:1]) + group[1:]}, header...)
+
+		// Hosts without the label come last
+		certificates = slices.Clone(certificates)
+		sort.SliceStable(certificates, func(i, j int) bool {
+			a, b := certificates[i].Labels[group], certificates[j].Labels[group]
+			if (a == "") != (b == "") {
+				return b == ""
+			}
+			return a < b
+		})
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(header)
+	if group != "" {
+		t.SetColumnConfigs([]table.ColumnConfig{{Number: 1, AutoMerge: true}})
+	}
+
+	statuses := make([]cert.Status, 0, len(certificates))
+	highlighted := make([]bool, 0, len(certificates))
+	for _, certInfo := range certificates {
+		status, _ := f.options.Thresholds.Evaluate(certInfo, now)
+		statuses = append(statuses, status)
+		highlighted = append(highlighted, f.options.Highlight[certInfo.Host])
+
+		host := certInfo.Host
+		if f.options.Highlight[host] && !f.colorEnabled() {
+			host = "* " + host
+		}
+
+		row := table.Row{
+			host,
+			certInfo.CommonName,
+			f.formatDNSNames(certInfo.DNSNames),
+			f.FormatTimestamp(certInfo.NotBefore),
+			f.FormatTimestamp(certInfo.NotAfter),
+			certInfo.DaysLeft(now),
+			relativeExpiry(certInfo, now),
+			certInfo.PublicKeyAlgorithm,
+			certInfo.Issuer,
+		}
+		if group != "" {
+			value := certInfo.Labels[group]
+			if value == "" {
+				value = "-"
+			}
+			row = append(table.Row{value}, row...)
+		}
+		t.AppendRows([]table.Row{row})
+	}
+
+	if f.colorEnabled() {
+		t.SetRowPainter(table.RowPainterWithAttributes(func(row table.Row, attr table.RowAttributes) text.Colors {
+			if attr.Number < 1 || attr.Number > len(statuses) {
+				return nil
+			}
+			colors := statusColors[statuses[attr.Number-1]]
+			if highlighted[attr.Number-1] {
+				colors = append(text.Colors{text.Bold}, colors...)
+			}
+			return colors
+		}))
+	}
+
+	// The summary spans all columns of the footer
+	lines := f.summaryLines(f.Summarize(result))
+	if group != "" {
+		lines = append(lines, fmt.Sprintf("By %s: %s", group, formatBreakdown(groupCounts(result, group))))
+	}
+	if result.ConfigVersion != "" {
+		lines = append(lines, "Config version: "+result.ConfigVersion)
+	}
+	summary := strings.Join(lines, "\n")
+	footer := make(table.Row, len(header))
+	for i := range footer {
+		footer[i] = summary
+	}
+	t.AppendFooter(footer, table.RowConfig{AutoMerge: true, AutoMergeAlign: text.AlignLeft})
+
+	t.Style().Format.Header = text.FormatDefault
+	t.Style().Format.Footer = text.FormatDefault
+	t.Render()
+
+	if len(result.Rotations) > 0 {
+		f.formatRotations(w, result.Rotations)
+	}
+
+	return nil
+}
+
+// groupCounts counts the hosts, failed or not, by the value of a label
+func groupCounts(result *cert.Result, label string) map[string]int {
+	counts := make(map[string]int)
+	for _, certInfo := range result.Certificates {
+		counts[certInfo.Labels[label]]++
+	}
+	for _, errInfo := range result.Errors {
+		counts[errInfo.Labels[label]]++
+	}
+	return counts
+}
+
+// formatRotations outputs the detected certificate rotations in a table
+func (f *Formatter) formatRotations(w io.Writer, rotations []cert.Rotation) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetTitle("Certificate Rotations")
+	t.AppendHeader(table.Row{"Host", "Detected At", "Old Certificate", "New Certificate"})
+
+	for _, rotation := range rotations {
+		t.AppendRow(table.Row{
+			rotation.Host,
+			f.FormatTimestamp(rotation.DetectedAt),
+			f.formatVersion(rotation.Old),
+			f.formatVersion(rotation.New),
+		})
+	}
+
+	t.Style().Format.Header = text.FormatDefault
+	t.Render()
+}
+
+// formatVersion describes a certificate version on several lines
+func (f *Formatter) formatVersion(version cert.CertificateVersion) string {
+	return strings.Join([]string{
+		"Serial " + version.SerialNumber,
+		"Expires " + f.FormatTimestamp(version.NotAfter),
+		version.Issuer,
+		version.PublicKeyAlgorithm,
+	}, "\n")
+}
+
+// formatDNSNames lists DNS names one per line, truncated in compact mode
+func (f *Formatter) formatDNSNames(dnsNames []string) string {
+	if f.options.Compact && len(dnsNames) > compactDNSNames {
+		shown := append([]string(nil), dnsNames[:compactDNSNames]...)
+		shown = append(shown, fmt.Sprintf("+%d more", len(dnsNames)-compactDNSNames))
+		return strings.Join(shown, "\n")
+	}
+
+	return strings.Join(dnsNames, "\n")
+}
+
+// FormatTimestamp formats a timestamp with the configured layout and time zone
+func (f *Formatter) FormatTimestamp(t time.Time) string {
+	layout := DefaultTimeFormat
+	if f.options.TimeFormat != "" {
+		layout = resolveLayout(f.options.TimeFormat)
+	}
+
+	return t.In(f.location()).Format(layout)
+}
+
+// location returns the configured time zone of timestamps, UTC by default
+func (f *Formatter) location() *time.Location {
+	if f.options.Location != nil {
+		return f.options.Location
+	}
+	return time.UTC
+}
+
+// summaryLines describes the statistics of a run for the table footer
+func (f *Formatter) summaryLines(summary cert.Summary) []string {
+	lines := []string{
+		fmt.Sprintf("%d host(s): %d ok, %d warning, %d critical, %d error(s)",
+			summary.Total, summary.OK, summary.Warning, summary.Critical, summary.Errors),
+	}
+
+	if expiry := summary.SoonestExpiry; expiry != nil {
+		lines = append(lines, fmt.Sprintf("Soonest expiry: %s, %s (%s)",
+			expiry.Host, f.FormatTimestamp(expiry.NotAfter), relativeExpiry(cert.CertificateInfo{NotAfter: expiry.NotAfter}, f.now())))
+	}
+
+	if len(summary.Issuers) > 0 {
+		lines = append(lines, "Issuers: "+formatBreakdown(summary.Issuers))
+	}
+
+	if len(summary.KeyAlgorithms) > 0 {
+		lines = append(lines, "Key algorithms: "+formatBreakdown(summary.KeyAlgorithms))
+	}
+
+	serials := make([]string, 0, len(summary.SharedSerials))
+	for serial := range summary.SharedSerials {
+		serials = append(serials, serial)
+	}
+	sort.Strings(serials)
+	for _, serial := range serials {
+		lines = append(lines, fmt.Sprintf("Shared serial %s: %s", serial, strings.Join(summary.SharedSerials[serial], ", ")))
+	}
+
+	return lines
+}
+
+// formatBreakdown lists counts by name, the most frequent first
+func formatBreakdown(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == "" {
+			parts = append(parts, fmt.Sprintf("unknown (%d)", counts[name]))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s (%d)", name, counts[name]))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,173 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+func TestFormatter_Format_TableStatus(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:      "ok.example.com:443",
+				DNSNames:  []string{"a.example.com", "b.example.com", "c.example.com", "d.example.com", "e.example.com"},
+				NotBefore: now.AddDate(0, -1, 0),
+				NotAfter:  now.AddDate(0, 0, 90),
+			},
+			{
+				Host:      "warning.example.com:443",
+				NotBefore: now.AddDate(0, -1, 0),
+				NotAfter:  now.AddDate(0, 0, 12),
+			},
+			{
+				Host:      "expired.example.com:443",
+				NotBefore: now.AddDate(0, -1, 0),
+				NotAfter:  now.AddDate(0, 0, -3),
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		options Options
+		noColor string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "days left and relative time",
+			want: []string{
+				"Days Left", "in 90 days", "in 12 days", "expired 3 days ago", "2024-05-29 00:00:00 UTC", "e.example.com",
+				"3 host(s): 1 ok, 1 warning, 1 critical, 0 error(s)",
+				"Soonest expiry: expired.example.com:443, 2024-05-29 00:00:00 UTC (expired 3 days ago)",
+			},
+			notWant: []string{"\033["},
+		},
+		{
+			name:    "compact",
+			options: Options{Compact: true},
+			want:    []string{"c.example.com", "+2 more"},
+			notWant: []string{"e.example.com"},
+		},
+		{
+			name:    "time format and location",
+			options: Options{TimeFormat: "DateTime", Location: time.FixedZone("UTC+8", 8*60*60)},
+			want:    []string{"2024-08-30 08:00:00"},
+		},
+		{
+			name:    "color",
+			options: Options{Color: true},
+			want:    []string{"\033[33m", "\033[31m"},
+		},
+		{
+			name:    "highlight",
+			options: Options{Highlight: map[string]bool{"warning.example.com:443": true}},
+			want:    []string{"* warning.example.com:443"},
+			notWant: []string{"* ok.example.com:443"},
+		},
+		{
+			name:    "highlight with color",
+			options: Options{Color: true, Highlight: map[string]bool{"warning.example.com:443": true}},
+			want:    []string{"\033[1;33m"},
+			notWant: []string{"* warning.example.com:443"},
+		},
+		{
+			name:    "NO_COLOR",
+			options: Options{Color: true},
+			noColor: "1",
+			notWant: []string{"\033["},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+
+			var buf bytes.Buffer
+			options := tt.options
+			options.Thresholds = cert.DefaultThresholds()
+			options.Out = &buf
+			formatter := NewWithOptions(options)
+			formatter.now = func() time.Time { return now }
+
+			if err := formatter.Format(result, "table"); err != nil {
+				t.Fatalf("Format() unexpected error: %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("table should contain %q:\n%s", want, buf.String())
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(buf.String(), notWant) {
+					t.Errorf("table should not contain %q:\n%s", notWant, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestFormatter_Format_TableRotations(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewWithOptions(Options{Thresholds: cert.DefaultThresholds(), Out: &buf})
+
+	notAfter := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{{Host: "example.com:443", SerialNumber: "02"}},
+		Rotations: []cert.Rotation{{
+			Host:       "example.com:443",
+			DetectedAt: notAfter.AddDate(0, -1, 0),
+			Old:        cert.CertificateVersion{SerialNumber: "01", NotAfter: notAfter, Issuer: "R3", PublicKeyAlgorithm: "RSA"},
+			New:        cert.CertificateVersion{SerialNumber: "02", NotAfter: notAfter.AddDate(0, 3, 0), Issuer: "E5", PublicKeyAlgorithm: "ECDSA"},
+		}},
+	}
+
+	if err := formatter.Format(result, "table"); err != nil {
+		t.Fatalf("Format() unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"Certificate Rotations", "Serial 01", "Serial 02", "Expires 2024-12-01 00:00:00 UTC", "E5", "ECDSA"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("table should contain %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestFormatter_Format_TableGroupBy(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewWithOptions(Options{Thresholds: cert.DefaultThresholds(), GroupBy: "team", Out: &buf})
+
+	notAfter := time.Now().AddDate(0, 3, 0)
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{Host: "unlabeled.example.com:443", NotAfter: notAfter},
+			{Host: "web.example.com:443", NotAfter: notAfter, Labels: map[string]string{"team": "web"}},
+			{Host: "db.example.com:443", NotAfter: notAfter, Labels: map[string]string{"team": "db"}},
+		},
+		Errors: []cert.ErrorInfo{{Host: "api.example.com:443", Error: "timeout", Labels: map[string]string{"team": "web"}}},
+	}
+
+	if err := formatter.Format(result, "table"); err != nil {
+		t.Fatalf("Format() unexpected error: %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{"Team", "By team: web (2), unknown (1), db (1)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("table should contain %q:\n%s", want, output)
+		}
+	}
+
+	db := strings.Index(output, "db.example.com")
+	web := strings.Index(output, "web.example.com")
+	unlabeled := strings.Index(output, "unlabeled.example.com")
+	if db < 0 || db > web || web > unlabeled {
+		t.Errorf("table rows should be sorted by team, unlabeled hosts last:\n%s", output)
+	}
+}
@@ -0,0 +1,107 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+var templateColors = map[string]string{
+	"bold":    "\033[1m",
+	"red":     "\033[31m",
+	"green":   "\033[32m",
+	"yellow":  "\033[33m",
+	"blue":    "\033[34m",
+	"magenta": "\033[35m",
+	"cyan":    "\033[36m",
+}
+
+// formatTemplate outputs the results through a user supplied Go text/template
+func (f *Formatter) formatTemplate(w io.Writer, result *cert.Result) error {
+	tmpl, err := f.parseTemplate()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, result); err != nil {
+		return fmt.Errorf("error executing template: %w", err)
+	}
+
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// CheckTemplate reports whether the configured template parses, so that
+// mistakes surface before any host is checked
+func (f *Formatter) CheckTemplate() error {
+	_, err := f.parseTemplate()
+	return err
+}
+
+// parseTemplate parses the configured template along with the helper functions
+func (f *Formatter) parseTemplate() (*template.Template, error) {
+	if f.options.Template == "" {
+		return nil, fmt.Errorf("no template provided")
+	}
+
+	tmpl, err := template.New("output").Funcs(f.templateFuncs()).Parse(f.options.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// templateFuncs returns the helper functions available to templates
+func (f *Formatter) templateFuncs() template.FuncMap {
+	now := f.now()
+	useColor := f.colorEnabled()
+
+	return template.FuncMap{
+		"daysLeft": func(certInfo cert.CertificateInfo) int {
+			return certInfo.DaysLeft(now)
+		},
+		"status": func(certInfo cert.CertificateInfo) string {
+			status, _ := f.options.Thresholds.Evaluate(certInfo, now)
+			return string(status)
+		},
+		"summary": func(result *cert.Result) cert.Summary {
+			return f.options.Thresholds.Summarize(result, now)
+		},
+		"until": func(t time.Time) time.Duration {
+			return t.Sub(now)
+		},
+		"humanizeDuration": humanizeDuration,
+		"relativeTime": func(t time.Time) string {
+			return relativeTime(t, now)
+		},
+		"formatDate": func(layout string, t time.Time) string {
+			return t.In(f.location()).Format(resolveLayout(layout))
+		},
+		"join": func(sep string, values []string) string {
+			return strings.Join(values, sep)
+		},
+		"color": func(name, text string) (string, error) {
+			code, ok := templateColors[name]
+			if !ok {
+				return "", fmt.Errorf("unknown color: %s", name)
+			}
+			if !useColor {
+				return text, nil
+			}
+			return code + text + "\033[0m", nil
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
@@ -0,0 +1,138 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+func TestFormatter_Format_Template(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{
+				Host:      "example.com:443",
+				DNSNames:  []string{"example.com", "www.example.com"},
+				NotBefore: now.AddDate(0, -1, 0),
+				NotAfter:  now.AddDate(0, 0, 12),
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "days left and status",
+			template: `{{range .Certificates}}{{.Host}} {{daysLeft .}} {{status .}}{{end}}`,
+			want:     "example.com:443 12 warning\n",
+		},
+		{
+			name:     "humanized durations",
+			template: `{{range .Certificates}}{{humanizeDuration (until .NotAfter)}}, {{relativeTime .NotBefore}}{{end}}`,
+			want:     "12 days, 31 days ago\n",
+		},
+		{
+			name:     "date formatting",
+			template: `{{range .Certificates}}{{formatDate "DateOnly" .NotAfter}} {{formatDate "02 Jan" .NotAfter}}{{end}}`,
+			want:     "2024-06-13 13 Jun\n",
+		},
+		{
+			name:     "joining names",
+			template: `{{range .Certificates}}{{.DNSNames | join ", " | upper}}{{end}}`,
+			want:     "EXAMPLE.COM, WWW.EXAMPLE.COM\n",
+		},
+		{
+			name:     "summary",
+			template: `{{with summary .}}{{.Total}} host(s), {{.Warning}} warning{{end}}`,
+			want:     "1 host(s), 1 warning\n",
+		},
+		{
+			name:     "coloring",
+			template: `{{color "red" "alert"}}`,
+			want:     "\033[31malert\033[0m\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			formatter := NewWithOptions(Options{
+				Thresholds: cert.DefaultThresholds(),
+				Template:   tt.template,
+				Color:      true,
+				Out:        &buf,
+			})
+			formatter.now = func() time.Time { return now }
+
+			if err := formatter.Format(result, "template"); err != nil {
+				t.Fatalf("Format() unexpected error: %v", err)
+			}
+
+			if buf.String() != tt.want {
+				t.Errorf("Format() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatter_Template_Location(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewWithOptions(Options{
+		Template: `{{range .Certificates}}{{formatDate "DateTime" .NotAfter}}{{end}}`,
+		Location: time.FixedZone("UTC+8", 8*60*60),
+		Out:      &buf,
+	})
+
+	result := &cert.Result{
+		Certificates: []cert.CertificateInfo{
+			{Host: "example.com:443", NotAfter: time.Date(2024, 8, 30, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	if err := formatter.Format(result, "template"); err != nil {
+		t.Fatalf("Format() unexpected error: %v", err)
+	}
+
+	if want := "2024-08-30 08:00:00\n"; buf.String() != want {
+		t.Errorf("Format() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestFormatter_Template_NoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	formatter := NewWithOptions(Options{Template: `{{color "green" "ok"}}`, Color: true})
+	tmpl, err := formatter.parseTemplate()
+	if err != nil {
+		t.Fatalf("parseTemplate() unexpected error: %v", err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, &cert.Result{}); err != nil {
+		t.Fatalf("Execute() unexpected error: %v", err)
+	}
+	if b.String() != "ok" {
+		t.Errorf("color should be disabled by NO_COLOR, got %q", b.String())
+	}
+}
+
+func TestFormatter_CheckTemplate(t *testing.T) {
+	if err := NewWithOptions(Options{}).CheckTemplate(); err == nil {
+		t.Error("CheckTemplate() should fail without a template")
+	}
+
+	if err := NewWithOptions(Options{Template: "{{ .Missing"}).CheckTemplate(); err == nil {
+		t.Error("CheckTemplate() should fail for an invalid template")
+	}
+
+	if err := NewWithOptions(Options{Template: "{{ len .Certificates }}"}).CheckTemplate(); err != nil {
+		t.Errorf("CheckTemplate() unexpected error: %v", err)
+	}
+}
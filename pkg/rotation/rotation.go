@@ -0,0 +1,111 @@
+package rotation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/internal/atomicfile"
+)
+
+const stateVersion = 1
+
+// Load reads the state file, a missing file starts with an empty state
+func Load(path string) (*Tracker, error) {
+	if path == "" {
+		return nil, fmt.Errorf("state file path cannot be empty")
+	}
+
+	t := &Tracker{
+		path:  path,
+		state: state{Version: stateVersion, Certificates: make(map[string]seen)},
+		now:   time.Now,
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &t.state); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %w", path, err)
+	}
+
+	if t.state.Version != stateVersion {
+		return nil, fmt.Errorf("unsupported state file version %d in %s", t.state.Version, path)
+	}
+
+	if t.state.Certificates == nil {
+		t.state.Certificates = make(map[string]seen)
+	}
+
+	return t, nil
+}
+
+// Key identifies a host and the server name it was checked with
+func Key(host, serverName string) string {
+	return host + "|" + serverName
+}
+
+// Observe records the certificate of a host, returning the rotation when it
+// differs from the one seen before
+func (t *Tracker) Observe(certInfo cert.CertificateInfo) *cert.Rotation {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+	key := Key(certInfo.Host, certInfo.ServerName)
+	current := certInfo.Version()
+
+	previous, found := t.state.Certificates[key]
+	t.state.Certificates[key] = seen{
+		Host:               certInfo.Host,
+		ServerName:         certInfo.ServerName,
+		CertificateVersion: current,
+		SeenAt:             now,
+	}
+
+	if !found || previous.SameCertificate(current) {
+		return nil
+	}
+
+	return &cert.Rotation{
+		Host:       certInfo.Host,
+		ServerName: certInfo.ServerName,
+		DetectedAt: now,
+		Old:        previous.CertificateVersion,
+		New:        current,
+	}
+}
+
+// ObserveResult records the certificates of a result and appends the
+// detected rotations to it
+func (t *Tracker) ObserveResult(result *cert.Result) {
+	for _, certInfo := range result.Certificates {
+		if rotation := t.Observe(certInfo); rotation != nil {
+			result.Rotations = append(result.Rotations, *rotation)
+		}
+	}
+}
+
+// Save atomically writes the state file
+func (t *Tracker) Save() error {
+	t.mutex.Lock()
+	data, err := json.MarshalIndent(t.state, "", "  ")
+	t.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("error marshaling state: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(t.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("cannot write state file: %w", err)
+	}
+
+	return nil
+}
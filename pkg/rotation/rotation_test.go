@@ -0,0 +1,97 @@
+package rotation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+func TestTracker_Observe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	original := cert.CertificateInfo{Host: "example.com:443", ServerName: "example.com", SerialNumber: "01", Fingerprint: "AA", Issuer: "R3", PublicKeyAlgorithm: "RSA", NotAfter: now.AddDate(0, 0, 10)}
+	renewed := cert.CertificateInfo{Host: "example.com:443", ServerName: "example.com", SerialNumber: "02", Fingerprint: "BB", Issuer: "R10", PublicKeyAlgorithm: "ECDSA", NotAfter: now.AddDate(0, 0, 90)}
+
+	tracker, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	tracker.now = func() time.Time { return now }
+
+	if rotation := tracker.Observe(original); rotation != nil {
+		t.Errorf("Observe() of a new host = %+v, want no rotation", rotation)
+	}
+	if err := tracker.Save(); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	// The state survives across runs
+	tracker, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	tracker.now = func() time.Time { return now.AddDate(0, 0, 1) }
+
+	if rotation := tracker.Observe(original); rotation != nil {
+		t.Errorf("Observe() of the same certificate = %+v, want no rotation", rotation)
+	}
+
+	rotation := tracker.Observe(renewed)
+	if rotation == nil {
+		t.Fatal("Observe() of a renewed certificate should report a rotation")
+	}
+	if rotation.Host != "example.com:443" || rotation.ServerName != "example.com" || !rotation.DetectedAt.Equal(now.AddDate(0, 0, 1)) {
+		t.Errorf("Observe() rotation = %+v", rotation)
+	}
+	if rotation.Old != original.Version() || rotation.New != renewed.Version() {
+		t.Errorf("Observe() rotation versions = %+v -> %+v", rotation.Old, rotation.New)
+	}
+
+	// Another server name on the same address is tracked separately
+	other := renewed
+	other.ServerName = "www.example.com"
+	if rotation := tracker.Observe(other); rotation != nil {
+		t.Errorf("Observe() of another server name = %+v, want no rotation", rotation)
+	}
+}
+
+func TestTracker_ObserveResult(t *testing.T) {
+	tracker, err := Load(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	tracker.ObserveResult(&cert.Result{Certificates: []cert.CertificateInfo{{Host: "example.com:443", SerialNumber: "01"}}})
+
+	result := &cert.Result{Certificates: []cert.CertificateInfo{{Host: "example.com:443", SerialNumber: "02"}}}
+	tracker.ObserveResult(result)
+
+	if len(result.Rotations) != 1 || result.Rotations[0].Old.SerialNumber != "01" || result.Rotations[0].New.SerialNumber != "02" {
+		t.Errorf("ObserveResult() rotations = %+v", result.Rotations)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Load(""); err == nil {
+		t.Error("Load() should fail without a path")
+	}
+
+	for name, content := range map[string]string{
+		"invalid.json": "{",
+		"version.json": `{"version": 99}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("Load(%s) expected error but got none", name)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package rotation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// Tracker remembers the certificate last seen for every host and server name
+// across runs, persisted in a state file
+type Tracker struct {
+	path  string
+	mutex sync.Mutex
+	state state
+	now   func() time.Time
+}
+
+type state struct {
+	Version      int             `json:"version"`
+	Certificates map[string]seen `json:"certificates"`
+}
+
+type seen struct {
+	Host       string `json:"host"`
+	ServerName string `json:"server_name,omitempty"`
+	cert.CertificateVersion
+	SeenAt time.Time `json:"seen_at"`
+}
@@ -0,0 +1,184 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchLimit bounds the search for the next activation, expressions such
+// as "0 0 30 2 *" never match
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	dayField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	weekdayField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// descriptors are the shorthands for common expressions
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a standard five field cron expression (minute, hour, day of
+// month, month, day of week) or one of the @hourly, @daily, @weekly,
+// @monthly and @yearly shorthands
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expr, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	s := &Schedule{}
+	var err error
+	if s.minutes, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if s.hours, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if s.days, err = parseField(fields[2], dayField); err != nil {
+		return nil, err
+	}
+	if s.months, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if s.weekdays, err = parseField(fields[4], weekdayField); err != nil {
+		return nil, err
+	}
+
+	// Sunday is both 0 and 7
+	if s.weekdays&(1<<7) != 0 {
+		s.weekdays |= 1
+	}
+
+	s.daysRestricted = !strings.HasPrefix(fields[2], "*")
+	s.weekdaysRestricted = !strings.HasPrefix(fields[4], "*")
+
+	return s, nil
+}
+
+// parseField parses a comma-separated list of values, ranges and steps
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepExpr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepExpr, f.name)
+			}
+			step = n
+		}
+
+		var low, high int
+		switch {
+		case rangeExpr == "*":
+			low, high = f.min, f.max
+		case strings.Contains(rangeExpr, "-"):
+			lowExpr, highExpr, _ := strings.Cut(rangeExpr, "-")
+			var err error
+			if low, err = f.value(lowExpr); err != nil {
+				return 0, err
+			}
+			if high, err = f.value(highExpr); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q in %s field", rangeExpr, f.name)
+			}
+		default:
+			value, err := f.value(rangeExpr)
+			if err != nil {
+				return 0, err
+			}
+			low, high = value, value
+			if hasStep {
+				high = f.max
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+
+	return bits, nil
+}
+
+// value parses a number or a name of the field
+func (f field) value(expr string) (int, error) {
+	if value, ok := f.names[strings.ToLower(expr)]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(expr)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field (allowed: %d-%d)", expr, f.name, f.min, f.max)
+	}
+
+	return value, nil
+}
+
+// Next returns the first activation after t in the time zone of t, the zero
+// time when the schedule never fires
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(searchLimit)
+
+	for t.Before(limit) {
+		if s.months&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hours&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minutes&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// matchDay reports whether the day of t matches the day fields
+func (s *Schedule) matchDay(t time.Time) bool {
+	day := s.days&(1<<t.Day()) != 0
+	weekday := s.weekdays&(1<<int(t.Weekday())) != 0
+
+	if s.daysRestricted && s.weekdaysRestricted {
+		return day || weekday
+	}
+	return day && weekday
+}
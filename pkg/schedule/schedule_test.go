@@ -0,0 +1,93 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr string
+	}{
+		{spec: "* * * * *"},
+		{spec: "*/15 9-17 * * mon-fri"},
+		{spec: "0 6 1,15 jan,jul *"},
+		{spec: "@daily"},
+		{spec: "@Weekly"},
+		{spec: "* * * *", wantErr: "expected 5 fields"},
+		{spec: "60 * * * *", wantErr: "invalid value \"60\" in minute field"},
+		{spec: "* 24 * * *", wantErr: "hour field"},
+		{spec: "* * 0 * *", wantErr: "day of month field"},
+		{spec: "* * * 13 *", wantErr: "month field"},
+		{spec: "* * * * 8", wantErr: "day of week field"},
+		{spec: "*/0 * * * *", wantErr: "invalid step"},
+		{spec: "5-1 * * * *", wantErr: "invalid range"},
+		{spec: "@reboot", wantErr: "expected 5 fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := Parse(tt.spec)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Parse() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2025, 1, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: "* * * * *", want: time.Date(2025, 1, 15, 10, 8, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", want: time.Date(2025, 1, 15, 10, 15, 0, 0, time.UTC)},
+		{spec: "5/20 * * * *", want: time.Date(2025, 1, 15, 10, 25, 0, 0, time.UTC)},
+		{spec: "@hourly", want: time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{spec: "@daily", want: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 9 * * mon", want: time.Date(2025, 1, 20, 9, 0, 0, 0, time.UTC)},
+		{spec: "0 9 * * 7", want: time.Date(2025, 1, 19, 9, 0, 0, 0, time.UTC)},
+		{spec: "30 8 1 * *", want: time.Date(2025, 2, 1, 8, 30, 0, 0, time.UTC)},
+		{spec: "0 0 29 feb *", want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Restricted day of month and day of week match either
+		{spec: "0 0 1 * fri", want: time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	never, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if got := never.Next(from); !got.IsZero() {
+		t.Errorf("Next() = %v, want the zero time", got)
+	}
+
+	// Activations are computed in the time zone of the given time
+	location := time.FixedZone("UTC+9", 9*60*60)
+	daily, _ := Parse("0 9 * * *")
+	if got := daily.Next(from.In(location)); !got.Equal(time.Date(2025, 1, 16, 9, 0, 0, 0, location)) {
+		t.Errorf("Next() = %v in %s", got, location)
+	}
+}
@@ -0,0 +1,23 @@
+package schedule
+
+// Schedule is a parsed cron expression, each field is a bitset of the
+// matching values
+type Schedule struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+
+	// Restricted day fields are OR'd together, as in cron(8)
+	daysRestricted     bool
+	weekdaysRestricted bool
+}
+
+// field describes the range and names of a cron field
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
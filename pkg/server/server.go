@@ -0,0 +1,244 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+	"github.com/guessi/ssl-certs-checker/pkg/output"
+)
+
+const (
+	DefaultListenAddress = ":9219"
+	DefaultInterval      = 5 * time.Minute
+	shutdownTimeout      = 5 * time.Second
+)
+
+// New creates a new exporter server checking the given hosts periodically
+func New(checker *cert.Checker, hosts []string, interval time.Duration) *Server {
+	return &Server{
+		checker:      checker,
+		hosts:        hosts,
+		interval:     interval,
+		now:          time.Now,
+		reconfigured: make(chan struct{}, 1),
+	}
+}
+
+// SetConfigVersion sets the version of the config file the hosts were
+// loaded from, reported along with the results
+func (s *Server) SetConfigVersion(version string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.configVersion = version
+}
+
+// Reconfigure replaces the checker and the hosts checked along with the
+// version of their config file, and checks the new hosts right away
+func (s *Server) Reconfigure(checker *cert.Checker, hosts []string, version string) {
+	s.mutex.Lock()
+	s.checker = checker
+	s.hosts = hosts
+	s.configVersion = version
+	s.mutex.Unlock()
+
+	select {
+	case s.reconfigured <- struct{}{}:
+	default:
+	}
+}
+
+// OnResult registers a function called with the result of every check
+func (s *Server) OnResult(fn func(ctx context.Context, result *cert.Result)) {
+	s.onResult = fn
+}
+
+// Run checks the configured hosts on schedule and serves HTTP requests on
+// the given address until the context is cancelled
+func (s *Server) Run(ctx context.Context, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", address, err)
+	}
+
+	return s.Serve(ctx, listener)
+}
+
+// Serve is like Run but accepts connections on an existing listener
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	httpServer := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go s.loop(ctx)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("HTTP server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("HTTP server shutdown failed: %w", err)
+	}
+
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("HTTP server failed: %w", err)
+	}
+
+	return nil
+}
+
+// Handler returns the HTTP handler exposing the exporter endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/probe", s.handleProbe)
+	mux.HandleFunc("/api/v1/results", s.handleResults)
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	return mux
+}
+
+// Result returns the latest cached result, nil until the first check completes
+func (s *Server) Result() *cert.Result {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.result
+}
+
+// loop refreshes the cached result immediately, then on every tick and
+// whenever the server is reconfigured
+func (s *Server) loop(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.reconfigured:
+			ticker.Reset(s.interval)
+		}
+	}
+}
+
+// config returns the checker, the hosts and the config version in use
+func (s *Server) config() (*cert.Checker, []string, string) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.checker, s.hosts, s.configVersion
+}
+
+// refresh runs a full check and caches its result, keeping the previous
+// result if the run could not complete
+func (s *Server) refresh(ctx context.Context) {
+	checker, hosts, version := s.config()
+
+	result, err := checker.CheckCertificates(ctx, hosts)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("certificate check failed: %v", err)
+		}
+		return
+	}
+	result.ConfigVersion = version
+
+	s.mutex.Lock()
+	s.result = result
+	s.mutex.Unlock()
+
+	if s.onResult != nil {
+		s.onResult(ctx, result)
+	}
+}
+
+// handleMetrics serves the cached result in the Prometheus exposition format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	result := s.Result()
+	if result == nil {
+		http.Error(w, "no results available yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := output.WritePrometheus(w, result, s.now()); err != nil {
+		log.Printf("failed to write metrics: %v", err)
+	}
+}
+
+// handleProbe checks a single target on demand, blackbox-exporter style
+func (s *Server) handleProbe(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		http.Error(w, "target parameter is missing", http.StatusBadRequest)
+		return
+	}
+
+	checker, _, _ := s.config()
+
+	start := time.Now()
+	result, err := checker.CheckCertificates(r.Context(), []string{target})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("probe failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	elapsed := time.Since(start)
+
+	success := 0
+	if len(result.Errors) == 0 && len(result.Certificates) > 0 {
+		success = 1
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprintf(w, "# HELP probe_success Whether the probe succeeded.\n")
+	fmt.Fprintf(w, "# TYPE probe_success gauge\n")
+	fmt.Fprintf(w, "probe_success %d\n", success)
+	fmt.Fprintf(w, "# HELP probe_duration_seconds Time the probe took to complete.\n")
+	fmt.Fprintf(w, "# TYPE probe_duration_seconds gauge\n")
+	fmt.Fprintf(w, "probe_duration_seconds %s\n", strconv.FormatFloat(elapsed.Seconds(), 'f', -1, 64))
+
+	if err := output.WritePrometheus(w, result, s.now()); err != nil {
+		log.Printf("failed to write probe metrics: %v", err)
+	}
+}
+
+// handleResults serves the cached result as JSON
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	result := s.Result()
+	if result == nil {
+		http.Error(w, "no results available yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		log.Printf("failed to write results: %v", err)
+	}
+}
+
+// handleHealthz reports that the server is up
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
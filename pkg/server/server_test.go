@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+// closedPortHost returns a local address nothing is listening on
+func closedPortHost(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	return address
+}
+
+func get(t *testing.T, handler http.Handler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	return recorder
+}
+
+func TestNew(t *testing.T) {
+	s := New(cert.New(time.Second, false), []string{"example.com"}, time.Minute)
+	if s == nil {
+		t.Fatal("New() returned nil")
+	}
+
+	if s.Result() != nil {
+		t.Error("New() should not have a cached result")
+	}
+}
+
+func TestServer_Handler_BeforeFirstCheck(t *testing.T) {
+	s := New(cert.New(time.Second, false), []string{"example.com"}, time.Minute)
+	handler := s.Handler()
+
+	for _, path := range []string{"/metrics", "/api/v1/results"} {
+		if code := get(t, handler, path).Code; code != http.StatusServiceUnavailable {
+			t.Errorf("GET %s status = %d, want %d", path, code, http.StatusServiceUnavailable)
+		}
+	}
+
+	recorder := get(t, handler, "/healthz")
+	if recorder.Code != http.StatusOK || strings.TrimSpace(recorder.Body.String()) != "ok" {
+		t.Errorf("GET /healthz = %d %q, want 200 ok", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestServer_Handler_AfterRefresh(t *testing.T) {
+	host := closedPortHost(t)
+	s := New(cert.New(time.Second, false), []string{host}, time.Minute)
+	s.refresh(context.Background())
+
+	handler := s.Handler()
+
+	recorder := get(t, handler, "/metrics")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /metrics status = %d, want 200", recorder.Code)
+	}
+	if !strings.Contains(recorder.Body.String(), `ssl_cert_check_success{host="`+host+`"} 0`) {
+		t.Errorf("GET /metrics should report the failed host:\n%s", recorder.Body.String())
+	}
+
+	recorder = get(t, handler, "/api/v1/results")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /api/v1/results status = %d, want 200", recorder.Code)
+	}
+
+	var result cert.Result
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("GET /api/v1/results returned invalid JSON: %v", err)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Host != host {
+		t.Errorf("GET /api/v1/results errors = %+v, want one error for %s", result.Errors, host)
+	}
+}
+
+func TestServer_OnResult(t *testing.T) {
+	host := closedPortHost(t)
+	s := New(cert.New(time.Second, false), []string{host}, time.Minute)
+
+	var results []*cert.Result
+	s.OnResult(func(ctx context.Context, result *cert.Result) {
+		results = append(results, result)
+	})
+	s.refresh(context.Background())
+
+	if len(results) != 1 || results[0] != s.Result() {
+		t.Errorf("OnResult() callback got %v, want the cached result", results)
+	}
+}
+
+func TestServer_Reconfigure(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	first, second := closedPortHost(t), closedPortHost(t)
+	s := New(cert.New(time.Second, false), []string{first}, time.Hour)
+	s.SetConfigVersion("v1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Serve(ctx, listener)
+
+	// waitForVersion waits for a result checked with the given config version
+	waitForVersion := func(version string) *cert.Result {
+		t.Helper()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if result := s.Result(); result != nil && result.ConfigVersion == version {
+				return result
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("no result with config version %q", version)
+		return nil
+	}
+
+	if result := waitForVersion("v1"); len(result.Errors) != 1 || result.Errors[0].Host != first {
+		t.Errorf("first result errors = %v, want a single error for %s", result.Errors, first)
+	}
+
+	// The new hosts are checked right away, not at the next tick an hour later
+	s.Reconfigure(cert.New(time.Second, false), []string{first, second}, "v2")
+	if result := waitForVersion("v2"); len(result.Errors) != 2 {
+		t.Errorf("reconfigured result errors = %v, want errors for %s and %s", result.Errors, first, second)
+	}
+}
+
+func TestServer_Handler_Probe(t *testing.T) {
+	s := New(cert.New(time.Second, false), nil, time.Minute)
+	handler := s.Handler()
+
+	if code := get(t, handler, "/probe").Code; code != http.StatusBadRequest {
+		t.Errorf("GET /probe without target status = %d, want %d", code, http.StatusBadRequest)
+	}
+
+	host := closedPortHost(t)
+	recorder := get(t, handler, "/probe?target="+host)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /probe status = %d, want 200", recorder.Code)
+	}
+
+	body := recorder.Body.String()
+	for _, line := range []string{"probe_success 0", `ssl_cert_check_success{host="` + host + `"} 0`} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("GET /probe output missing %q:\n%s", line, body)
+		}
+	}
+}
+
+func TestServer_Serve_GracefulShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	s := New(cert.New(time.Second, false), []string{closedPortHost(t)}, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Serve(ctx, listener)
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/healthz")
+	if err != nil {
+		t.Fatalf("GET /healthz failed: %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve() unexpected error: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Serve() did not return after cancellation")
+	}
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/guessi/ssl-certs-checker/pkg/cert"
+)
+
+type Server struct {
+	interval time.Duration
+	now      func() time.Time
+	onResult func(ctx context.Context, result *cert.Result)
+	// reconfigured triggers a refresh when the hosts changed
+	reconfigured chan struct{}
+
+	mutex         sync.RWMutex
+	checker       *cert.Checker
+	hosts         []string
+	configVersion string
+	result        *cert.Result
+}